@@ -0,0 +1,333 @@
+package errors
+
+import (
+    "bytes"
+    "encoding/binary"
+    "encoding/gob"
+    "encoding/json"
+    "fmt"
+)
+
+const (
+    binaryMagic   = 'E'
+    binaryVersion = 1
+
+    binaryFlagPanic   = 1 << 0
+    binaryFlagWrapped = 1 << 1
+)
+
+func init() {
+    gob.Register(&GenericError{})
+}
+
+// binaryEncoder writes an error chain as a string table followed by the
+// errors, outermost first. Strings, most of all the file and function names
+// of the stacktraces, are written once and referred to by index.
+type binaryEncoder struct {
+    strings []string
+    index   map[string]uint64
+    body    bytes.Buffer
+}
+
+func (enc *binaryEncoder) uvarint(v uint64) {
+    var b [binary.MaxVarintLen64]byte
+    n := binary.PutUvarint(b[:], v)
+    enc.body.Write(b[:n])
+}
+
+func (enc *binaryEncoder) string(s string) {
+    i, ok := enc.index[s]
+    if !ok {
+        i = uint64(len(enc.strings))
+        enc.strings = append(enc.strings, s)
+        enc.index[s] = i
+    }
+    enc.uvarint(i)
+}
+
+func (enc *binaryEncoder) bytes(b []byte) {
+    enc.uvarint(uint64(len(b)))
+    enc.body.Write(b)
+}
+
+func (enc *binaryEncoder) json(v interface{}) error {
+    if v == nil {
+        enc.bytes(nil)
+        return nil
+    }
+
+    b, err := json.Marshal(v)
+    if err != nil {
+        return err
+    }
+    enc.bytes(b)
+
+    return nil
+}
+
+func (enc *binaryEncoder) error(e *GenericError) error {
+    enc.string(e.Code)
+    enc.string(e.errType)
+    enc.string(e.Message)
+    enc.string(e.op)
+
+    var flags uint64
+    if e.panic {
+        flags |= binaryFlagPanic
+    }
+    if e.wrapped {
+        flags |= binaryFlagWrapped
+    }
+    enc.uvarint(flags)
+    enc.uvarint(uint64(e.severity))
+
+    if err := enc.json(e.input); err != nil {
+        return err
+    }
+    inputType := ""
+    if e.input != nil {
+        inputType = inputTypeName(e.input)
+    }
+    enc.string(inputType)
+
+    var fields, violations, extra interface{}
+    if len(e.fields) > 0 {
+        fields = e.fields
+    }
+    if len(e.violations) > 0 {
+        violations = e.violations
+    }
+    if len(e.extra) > 0 {
+        extra = e.extra
+    }
+    if err := enc.json(fields); err != nil {
+        return err
+    }
+    if err := enc.json(violations); err != nil {
+        return err
+    }
+    if err := enc.json(extra); err != nil {
+        return err
+    }
+
+    enc.string(e.traceID)
+    enc.string(e.spanID)
+    if e.remote {
+        enc.string(e.origin)
+    } else {
+        enc.string(ServiceName())
+    }
+
+    enc.stack(e.stacktrace)
+    enc.stack(e.asyncStack)
+
+    return nil
+}
+
+func (enc *binaryEncoder) stack(stack Stacktrace) {
+    enc.uvarint(uint64(len(stack)))
+    for _, frame := range stack {
+        enc.string(frame.Filename)
+        enc.string(frame.Function)
+        enc.string(frame.Module)
+        enc.uvarint(uint64(frame.Lineno))
+    }
+}
+
+// MarshalBinary encodes the error chain in a compact binary form. Errors that
+// are not *GenericError are encoded with their JSON representation.
+func (e *GenericError) MarshalBinary() ([]byte, error) {
+    enc := &binaryEncoder{index: make(map[string]uint64)}
+
+    chain := make([]*GenericError, 0, 4)
+    var cause Error = e
+    for cause != nil {
+        cerr, ok := cause.(*GenericError)
+        if !ok {
+            cerr = parseJSONError(cause.JSON())
+        }
+        chain = append(chain, cerr)
+        cause = cerr.cause
+    }
+
+    enc.uvarint(uint64(len(chain)))
+    for _, cerr := range chain {
+        if err := enc.error(cerr); err != nil {
+            return nil, err
+        }
+    }
+
+    out := &bytes.Buffer{}
+    out.WriteByte(binaryMagic)
+    out.WriteByte(binaryVersion)
+
+    var b [binary.MaxVarintLen64]byte
+    out.Write(b[:binary.PutUvarint(b[:], uint64(len(enc.strings)))])
+    for _, s := range enc.strings {
+        out.Write(b[:binary.PutUvarint(b[:], uint64(len(s)))])
+        out.WriteString(s)
+    }
+    out.Write(enc.body.Bytes())
+
+    return out.Bytes(), nil
+}
+
+type binaryDecoder struct {
+    b       []byte
+    strings []string
+    err     error
+}
+
+func (dec *binaryDecoder) uvarint() uint64 {
+    if dec.err != nil {
+        return 0
+    }
+
+    v, n := binary.Uvarint(dec.b)
+    if n <= 0 {
+        dec.err = fmt.Errorf("errors: malformed binary error")
+        return 0
+    }
+    dec.b = dec.b[n:]
+
+    return v
+}
+
+func (dec *binaryDecoder) bytes() []byte {
+    n := dec.uvarint()
+    if dec.err != nil {
+        return nil
+    }
+    if uint64(len(dec.b)) < n {
+        dec.err = fmt.Errorf("errors: malformed binary error")
+        return nil
+    }
+
+    b := dec.b[:n]
+    dec.b = dec.b[n:]
+
+    return b
+}
+
+func (dec *binaryDecoder) string() string {
+    i := dec.uvarint()
+    if dec.err != nil {
+        return ""
+    }
+    if i >= uint64(len(dec.strings)) {
+        dec.err = fmt.Errorf("errors: malformed binary error")
+        return ""
+    }
+
+    return dec.strings[i]
+}
+
+func (dec *binaryDecoder) json(v interface{}) {
+    b := dec.bytes()
+    if dec.err != nil || len(b) == 0 {
+        return
+    }
+
+    dec.err = json.Unmarshal(b, v)
+}
+
+func (dec *binaryDecoder) error() *GenericError {
+    e := &GenericError{
+        Code:    dec.string(),
+        errType: dec.string(),
+        Message: dec.string(),
+        op:      dec.string(),
+    }
+
+    flags := dec.uvarint()
+    e.panic = flags&binaryFlagPanic != 0
+    e.wrapped = flags&binaryFlagWrapped != 0
+    e.severity = Severity(dec.uvarint())
+
+    rawInput := dec.bytes()
+    inputType := dec.string()
+    if dec.err == nil && len(rawInput) > 0 {
+        input, ok, err := decodeInput(inputType, rawInput)
+        if err == nil && !ok {
+            err = json.Unmarshal(rawInput, &input)
+        }
+        e.input, dec.err = input, err
+    }
+
+    dec.json(&e.fields)
+    dec.json(&e.violations)
+    dec.json(&e.extra)
+
+    e.traceID = dec.string()
+    e.spanID = dec.string()
+    e.received(dec.string())
+
+    e.stacktrace = dec.stack()
+    e.asyncStack = dec.stack()
+
+    return e
+}
+
+func (dec *binaryDecoder) stack() Stacktrace {
+    n := dec.uvarint()
+    if dec.err != nil || n == 0 {
+        return nil
+    }
+    if n > uint64(len(dec.b)) {
+        dec.err = fmt.Errorf("errors: malformed binary error")
+        return nil
+    }
+
+    stack := make(Stacktrace, n)
+    for i := range stack {
+        stack[i] = &StacktraceFrame{
+            Filename: dec.string(),
+            Function: dec.string(),
+            Module:   dec.string(),
+            Lineno:   int(dec.uvarint()),
+        }
+    }
+
+    return stack
+}
+
+// UnmarshalBinary decodes an error chain encoded by MarshalBinary. Like
+// ParseJSONError, it marks the errors as remote.
+func (e *GenericError) UnmarshalBinary(data []byte) error {
+    if len(data) < 2 || data[0] != binaryMagic {
+        return fmt.Errorf("errors: malformed binary error")
+    }
+    if data[1] != binaryVersion {
+        return fmt.Errorf("errors: unsupported binary error version %d", data[1])
+    }
+
+    dec := &binaryDecoder{b: data[2:]}
+    n := dec.uvarint()
+    for i := uint64(0); i < n && dec.err == nil; i++ {
+        dec.strings = append(dec.strings, string(dec.bytes()))
+    }
+
+    n = dec.uvarint()
+    if dec.err == nil && (n == 0 || n > uint64(len(dec.b))) {
+        dec.err = fmt.Errorf("errors: malformed binary error")
+    }
+    if dec.err != nil {
+        return dec.err
+    }
+
+    root := dec.error()
+    last := root
+    for i := uint64(1); i < n; i++ {
+        cerr := dec.error()
+        last.cause = cerr
+        last = cerr
+    }
+    if dec.err != nil {
+        return dec.err
+    }
+
+    root.receivedAt = receivedAt(1)
+    *e = *root
+
+    return nil
+}
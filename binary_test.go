@@ -0,0 +1,55 @@
+package errors
+
+import (
+    "bytes"
+    "encoding/gob"
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestBinary(t *testing.T) {
+    defer resetInputTypes()
+    RegisterInputType("wireInput", wireInput{})
+
+    err := x().(Error).
+        WithInput(wireInput{ID: "a", Count: 1}).
+        WithField("tenant", "t1").
+        WithPanic()
+
+    b, berr := err.(*GenericError).MarshalBinary()
+    require.NoError(t, berr)
+
+    jsonB, _ := json.Marshal(err)
+    require.True(t, len(b) < len(jsonB))
+
+    newErr := &GenericError{}
+    require.NoError(t, newErr.UnmarshalBinary(b))
+    require.Equal(t, ParseJSONError(err.JSON()).JSON(), newErr.JSON())
+    require.Equal(t, wireInput{ID: "a", Count: 1}, newErr.GetInput())
+    require.True(t, newErr.IsPanic())
+    require.EqualError(t, newErr.RootError(), "err0")
+
+    require.Error(t, newErr.UnmarshalBinary(b[:len(b)/2]))
+    require.Error(t, newErr.UnmarshalBinary([]byte("{}")))
+
+    b[1] = binaryVersion + 1
+    require.EqualError(t, newErr.UnmarshalBinary(b), "errors: unsupported binary error version 2")
+}
+
+func TestGob(t *testing.T) {
+    type job struct {
+        ID  string
+        Err Error
+    }
+
+    err := x().(Error)
+    buf := &bytes.Buffer{}
+    require.NoError(t, gob.NewEncoder(buf).Encode(&job{ID: "job1", Err: err}))
+
+    decoded := &job{}
+    require.NoError(t, gob.NewDecoder(buf).Decode(decoded))
+    require.Equal(t, "job1", decoded.ID)
+    require.Equal(t, err.JSON(), decoded.Err.JSON())
+}
@@ -0,0 +1,211 @@
+package errors
+
+import (
+    "sync"
+    "time"
+)
+
+// BreakerState is the state of a Breaker.
+type BreakerState int
+
+const (
+    BreakerClosed BreakerState = iota
+    BreakerOpen
+    BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+    switch s {
+    case BreakerClosed:
+        return "closed"
+    case BreakerOpen:
+        return "open"
+    case BreakerHalfOpen:
+        return "half-open"
+    }
+
+    return "unknown"
+}
+
+// ErrBreakerOpen is returned by Breaker.Do without calling the function while
+// the circuit is open.
+var ErrBreakerOpen = DefInternalError("BreakerOpen", "circuit breaker is open")
+
+// Clock tells the current time. Breaker uses it to decide when an open circuit
+// may be retried, so tests can substitute a fake one.
+type Clock interface {
+    Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+    return time.Now()
+}
+
+// DefaultBreakerClassifier counts errors with an InternalErrorType or
+// TimeoutType error in their chain as failures. Every other type, e.g.
+// NotFoundType, is a healthy response. ErrBreakerOpen is not a failure, so
+// that a breaker does not count the rejections of another nested in it.
+func DefaultBreakerClassifier(err Error) bool {
+    if ErrBreakerOpen.Is(err) {
+        return false
+    }
+
+    return err.IsType(InternalErrorType) || err.IsType(TimeoutType)
+}
+
+// A Breaker is a circuit breaker guarding calls to a downstream dependency.
+//
+// The circuit opens after threshold consecutive failures, as decided by the
+// classifier. While open, Do returns ErrBreakerOpen immediately. Once the
+// open timeout has elapsed a single trial call is let through; its success
+// closes the circuit and its failure opens it again.
+type Breaker struct {
+    threshold     int
+    openTimeout   time.Duration
+    isFailure     func(err Error) bool
+    onStateChange func(from, to BreakerState)
+    clock         Clock
+
+    mu       sync.Mutex
+    state    BreakerState
+    failures int
+    openedAt time.Time
+    trial    bool
+}
+
+func NewBreaker(threshold int, openTimeout time.Duration) *Breaker {
+    if threshold < 1 {
+        threshold = 1
+    }
+
+    return &Breaker{
+        threshold:   threshold,
+        openTimeout: openTimeout,
+        isFailure:   DefaultBreakerClassifier,
+        clock:       systemClock{},
+    }
+}
+
+// WithClassifier sets the function deciding which errors count as failures.
+func (b *Breaker) WithClassifier(isFailure func(err Error) bool) *Breaker {
+    b.isFailure = isFailure
+
+    return b
+}
+
+// WithClock replaces the clock used to time the open state.
+func (b *Breaker) WithClock(clock Clock) *Breaker {
+    b.clock = clock
+
+    return b
+}
+
+// OnStateChange registers fn to be called on every state transition. It is
+// called with the breaker's lock held and must not call back into it.
+func (b *Breaker) OnStateChange(fn func(from, to BreakerState)) *Breaker {
+    b.onStateChange = fn
+
+    return b
+}
+
+// State returns the current state, moving an open circuit whose timeout has
+// elapsed to half-open.
+func (b *Breaker) State() BreakerState {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    b.advance()
+
+    return b.state
+}
+
+// Do calls f if the circuit allows it and records the outcome. A panic in f
+// is recorded as a failure before it propagates.
+func (b *Breaker) Do(f func() Error) Error {
+    if !b.allow() {
+        return ErrBreakerOpen.New()
+    }
+
+    panicked := true
+    defer func() {
+        if panicked {
+            b.record(true)
+        }
+    }()
+
+    err := f()
+    panicked = false
+    b.record(err != nil && b.isFailure(err))
+
+    return err
+}
+
+func (b *Breaker) allow() bool {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    b.advance()
+
+    switch b.state {
+    case BreakerOpen:
+        return false
+    case BreakerHalfOpen:
+        if b.trial {
+            return false
+        }
+        b.trial = true
+    }
+
+    return true
+}
+
+func (b *Breaker) record(failed bool) {
+    b.mu.Lock()
+    defer b.mu.Unlock()
+
+    switch b.state {
+    case BreakerHalfOpen:
+        b.trial = false
+        if failed {
+            b.open()
+            return
+        }
+        b.failures = 0
+        b.setState(BreakerClosed)
+    case BreakerClosed:
+        if !failed {
+            b.failures = 0
+            return
+        }
+        b.failures++
+        if b.failures >= b.threshold {
+            b.open()
+        }
+    }
+}
+
+func (b *Breaker) advance() {
+    if b.state == BreakerOpen && !b.clock.Now().Before(b.openedAt.Add(b.openTimeout)) {
+        b.setState(BreakerHalfOpen)
+    }
+}
+
+func (b *Breaker) open() {
+    b.failures = 0
+    b.openedAt = b.clock.Now()
+    b.setState(BreakerOpen)
+}
+
+func (b *Breaker) setState(state BreakerState) {
+    if b.state == state {
+        return
+    }
+
+    from := b.state
+    b.state = state
+    if b.onStateChange != nil {
+        b.onStateChange(from, state)
+    }
+}
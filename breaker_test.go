@@ -0,0 +1,114 @@
+package errors
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+    now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+    return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+    c.now = c.now.Add(d)
+}
+
+func TestBreaker(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    var transitions []string
+    b := NewBreaker(2, time.Second).WithClock(clock).OnStateChange(func(from, to BreakerState) {
+        transitions = append(transitions, from.String()+"->"+to.String())
+    })
+
+    internal := func() Error { return InternalError("code1", "err1") }
+    notFound := func() Error { return NotFound("code2", "err2") }
+    ok := func() Error { return nil }
+
+    require.EqualError(t, b.Do(notFound), "code2: err2")
+    require.EqualError(t, b.Do(notFound), "code2: err2")
+    require.Equal(t, BreakerClosed, b.State())
+
+    require.EqualError(t, b.Do(internal), "code1: err1")
+    require.Equal(t, BreakerClosed, b.State())
+    require.EqualError(t, b.Do(internal), "code1: err1")
+    require.Equal(t, BreakerOpen, b.State())
+
+    called := false
+    err := b.Do(func() Error { called = true; return nil })
+    require.False(t, called)
+    require.True(t, ErrBreakerOpen.Is(err))
+
+    clock.Add(time.Second)
+    require.Equal(t, BreakerHalfOpen, b.State())
+    require.EqualError(t, b.Do(internal), "code1: err1")
+    require.Equal(t, BreakerOpen, b.State())
+
+    clock.Add(time.Second)
+    require.Nil(t, b.Do(ok))
+    require.Equal(t, BreakerClosed, b.State())
+
+    require.Equal(t, []string{
+        "closed->open",
+        "open->half-open",
+        "half-open->open",
+        "open->half-open",
+        "half-open->closed",
+    }, transitions)
+}
+
+func TestBreakerClassifier(t *testing.T) {
+    b := NewBreaker(1, time.Second).WithClassifier(func(err Error) bool {
+        return err.GetType() == NotFoundType
+    })
+
+    _ = b.Do(func() Error { return InternalError("code1", "err1") })
+    require.Equal(t, BreakerClosed, b.State())
+
+    _ = b.Do(func() Error { return NotFound("code2", "err2") })
+    require.Equal(t, BreakerOpen, b.State())
+}
+
+func TestDefaultBreakerClassifier(t *testing.T) {
+    require.True(t, DefaultBreakerClassifier(Timeout("code1", "err1")))
+    require.True(t, DefaultBreakerClassifier(Wrap(InternalError("code1", "err1"), "calling")))
+    require.True(t, DefaultBreakerClassifier(WrapWithDef(DefNotFound("code2", "err2"), Timeout("code1", "err1"))))
+    require.False(t, DefaultBreakerClassifier(NotFound("code2", "err2")))
+    require.False(t, DefaultBreakerClassifier(ErrBreakerOpen.New()))
+    require.False(t, DefaultBreakerClassifier(Wrap(ErrBreakerOpen.New(), "calling")))
+
+    inner := NewBreaker(1, time.Minute)
+    outer := NewBreaker(2, time.Minute)
+    call := func() Error {
+        return inner.Do(func() Error { return Timeout("code1", "err1") })
+    }
+    _ = outer.Do(call)
+    require.Equal(t, BreakerOpen, inner.State())
+    for i := 0; i < 3; i++ {
+        require.True(t, ErrBreakerOpen.Is(outer.Do(call)))
+    }
+    require.Equal(t, BreakerClosed, outer.State())
+}
+
+func TestBreakerPanic(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    b := NewBreaker(1, time.Second).WithClock(clock)
+
+    require.Error(t, b.Do(func() Error { return InternalError("code1", "err1") }))
+    clock.Add(time.Second)
+    require.Equal(t, BreakerHalfOpen, b.State())
+
+    require.Panics(t, func() {
+        _ = b.Do(func() Error { panic("boom") })
+    })
+    require.Equal(t, BreakerOpen, b.State())
+
+    clock.Add(time.Second)
+    require.Nil(t, b.Do(func() Error { return nil }))
+    require.Equal(t, BreakerClosed, b.State())
+}
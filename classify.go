@@ -0,0 +1,118 @@
+package errors
+
+import (
+    "context"
+    "encoding/json"
+    stderrors "errors"
+    "io"
+    "net"
+    "net/url"
+    "os"
+    "strconv"
+    "sync"
+)
+
+// A Classifier returns the type and code of the errors it recognizes.
+type Classifier func(err error) (errType, code string, ok bool)
+
+var (
+    classifiersMu sync.RWMutex
+    classifiers   []Classifier
+)
+
+// RegisterClassifier adds c to the classifiers used by Classify. Classifiers
+// registered last run first, all of them before the built-in ones.
+func RegisterClassifier(c Classifier) {
+    classifiersMu.Lock()
+    classifiers = append(classifiers, c)
+    classifiersMu.Unlock()
+}
+
+// ResetClassifiers removes the classifiers added by RegisterClassifier.
+func ResetClassifiers() {
+    classifiersMu.Lock()
+    classifiers = nil
+    classifiersMu.Unlock()
+}
+
+// Classify returns the type and code matching err. Errors keep their own type
+// and code, errors of the standard library are recognized anywhere in the
+// chain of err, and any other error is GenericCode with no type.
+func Classify(err error) (errType, code string) {
+    if err == nil {
+        return NoneType, ""
+    }
+    if xerr, ok := err.(Error); ok {
+        return xerr.GetType(), xerr.GetCode()
+    }
+
+    classifiersMu.RLock()
+    custom := classifiers
+    classifiersMu.RUnlock()
+
+    for i := len(custom) - 1; i >= 0; i-- {
+        if errType, code, ok := custom[i](err); ok {
+            return errType, code
+        }
+    }
+    if errType, code, ok := classifyStd(err); ok {
+        return errType, code
+    }
+
+    return NoneType, GenericCode
+}
+
+// WrapTyped is Wrap with the type and code given by Classify. It returns
+// Errors unchanged and nil for a nil err.
+func WrapTyped(err error) Error {
+    if err == nil {
+        return nil
+    }
+    if xerr, ok := err.(Error); ok {
+        return xerr
+    }
+
+    errType, code := Classify(err)
+
+    return created(adapt(err, errType, code, 2))
+}
+
+// classifyStd recognizes the errors of the standard library. A cancelled
+// context is a TimeoutType error: the caller gave up waiting.
+func classifyStd(err error) (errType, code string, ok bool) {
+    var (
+        netErr    net.Error
+        urlErr    *url.Error
+        opErr     *net.OpError
+        syntaxErr *json.SyntaxError
+        typeErr   *json.UnmarshalTypeError
+        numErr    *strconv.NumError
+    )
+
+    switch {
+    case stderrors.Is(err, context.DeadlineExceeded):
+        return TimeoutType, "DeadlineExceeded", true
+    case stderrors.Is(err, context.Canceled):
+        return TimeoutType, "Canceled", true
+    case stderrors.Is(err, os.ErrNotExist):
+        return NotFoundType, "NotExist", true
+    case stderrors.Is(err, os.ErrExist):
+        return ConflictType, "AlreadyExists", true
+    case stderrors.Is(err, os.ErrPermission):
+        return ForbiddenType, "PermissionDenied", true
+    case stderrors.As(err, &netErr) && netErr.Timeout():
+        return TimeoutType, "NetworkTimeout", true
+    case stderrors.Is(err, io.ErrUnexpectedEOF):
+        return BadRequestType, "UnexpectedEOF", true
+    case stderrors.As(err, &syntaxErr), stderrors.As(err, &typeErr):
+        return BadRequestType, "InvalidJSON", true
+    case stderrors.As(err, &numErr):
+        return BadRequestType, "InvalidNumber", true
+    case stderrors.As(err, &urlErr):
+        return InternalErrorType, "RequestFailed", true
+    case stderrors.As(err, &opErr):
+        return InternalErrorType, "NetworkError", true
+    }
+
+    return NoneType, "", false
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+    "context"
+    "encoding/json"
+    stderrors "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "os"
+    "strconv"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+type timeoutErr struct{}
+
+func (timeoutErr) Error() string   { return "i/o timeout" }
+func (timeoutErr) Timeout() bool   { return true }
+func (timeoutErr) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+    _, openErr := os.Open("/does/not/exist")
+    var syntaxErr error = &json.SyntaxError{}
+    _, numErr := strconv.Atoi("x")
+
+    for _, tc := range []struct {
+        err     error
+        errType string
+        code    string
+    }{
+        {context.DeadlineExceeded, TimeoutType, "DeadlineExceeded"},
+        {fmt.Errorf("query: %w", context.Canceled), TimeoutType, "Canceled"},
+        {openErr, NotFoundType, "NotExist"},
+        {os.ErrPermission, ForbiddenType, "PermissionDenied"},
+        {os.ErrExist, ConflictType, "AlreadyExists"},
+        {&net.OpError{Op: "dial", Err: timeoutErr{}}, TimeoutType, "NetworkTimeout"},
+        {&net.OpError{Op: "dial", Err: fmt.Errorf("refused")}, InternalErrorType, "NetworkError"},
+        {io.ErrUnexpectedEOF, BadRequestType, "UnexpectedEOF"},
+        {&url.Error{Op: "Get", URL: "http://x", Err: fmt.Errorf("refused")}, InternalErrorType, "RequestFailed"},
+        {&url.Error{Op: "Get", URL: "http://x", Err: context.DeadlineExceeded}, TimeoutType, "DeadlineExceeded"},
+        {syntaxErr, BadRequestType, "InvalidJSON"},
+        {json.Unmarshal([]byte(`"x"`), new(int)), BadRequestType, "InvalidJSON"},
+        {numErr, BadRequestType, "InvalidNumber"},
+        {fmt.Errorf("other"), NoneType, GenericCode},
+        {NotFound("code1", "err1"), NotFoundType, "code1"},
+    } {
+        errType, code := Classify(tc.err)
+        require.Equal(t, tc.errType, errType, "%v", tc.err)
+        require.Equal(t, tc.code, code, "%v", tc.err)
+    }
+}
+
+func TestRegisterClassifier(t *testing.T) {
+    defer ResetClassifiers()
+
+    errQuota := fmt.Errorf("quota exceeded")
+    RegisterClassifier(func(err error) (string, string, bool) {
+        return ForbiddenType, "QuotaExceeded", err == errQuota
+    })
+    RegisterClassifier(func(err error) (string, string, bool) {
+        return NotFoundType, "Missing", err == os.ErrNotExist
+    })
+
+    errType, code := Classify(errQuota)
+    require.Equal(t, ForbiddenType, errType)
+    require.Equal(t, "QuotaExceeded", code)
+    _, code = Classify(os.ErrNotExist)
+    require.Equal(t, "Missing", code)
+
+    ResetClassifiers()
+    _, code = Classify(os.ErrNotExist)
+    require.Equal(t, "NotExist", code)
+}
+
+func TestWrapTyped(t *testing.T) {
+    require.Nil(t, WrapTyped(nil))
+
+    err := WrapTyped(context.DeadlineExceeded)
+    require.EqualError(t, err, "DeadlineExceeded: context deadline exceeded")
+    require.Equal(t, TimeoutType, err.GetType())
+    require.Equal(t, 441, HttpStatus(err.GetType()))
+    require.Equal(t, "TestWrapTyped", err.GetStacktrace()[len(err.GetStacktrace())-1].Function)
+    require.True(t, stderrors.Is(err, context.DeadlineExceeded))
+
+    opErr := &net.OpError{Op: "dial", Net: "tcp", Err: stderrors.New("connection refused")}
+    var target *net.OpError
+    require.True(t, stderrors.As(WrapTyped(opErr), &target))
+    require.Equal(t, opErr, target)
+
+    xerr := NotFound("code1", "err1")
+    require.Equal(t, xerr, WrapTyped(xerr))
+}
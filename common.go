@@ -1,5 +1,7 @@
 package errors
 
+import "fmt"
+
 func Is(err, target error) bool {
     u, ok := err.(Error)
     if !ok {
@@ -30,10 +32,116 @@ func Unwrap(err error) error {
     return u.Unwrap()
 }
 
-func Wrap(err error) Error {
-    return &GenericError{
+// Wrap returns an error with message msg caused by err, or nil if err is nil.
+// The error has the type of err and no code of its own, so that it reads as
+// "msg: " followed by the messages of err.
+//
+// Without msg, Wrap adds no layer: an Error is returned as is, with its own
+// code and stacktrace, rather than as a new GenericCode error. Any other err
+// becomes a GenericCode Error with the message of err and a stacktrace
+// starting at the caller, which unwraps to err.
+func Wrap(err error, msg ...string) Error {
+    if len(msg) == 0 {
+        return wrap(err, 2)
+    }
+
+    return wrapMessage(err, msg[0], 2)
+}
+
+func Wrapf(err error, format string, args ...interface{}) Error {
+    return wrapMessage(err, fmt.Sprintf(format, args...), 2)
+}
+
+// WrapWithDef returns an error of def caused by err, or nil if err is nil.
+// Definitions without a message take the message of err.
+func WrapWithDef(def *ErrorDefinition, err error) Error {
+    if err == nil {
+        return nil
+    }
+
+    return wrapDef(def, err, 2)
+}
+
+// WrapType returns an error with the given type and code and the message of
+// err, caused by err, or nil if err is nil.
+func WrapType(err error, errType, code string) Error {
+    if err == nil {
+        return nil
+    }
+
+    return wrapType(err, errType, code, 2)
+}
+
+func wrapDef(def *ErrorDefinition, err error, skip int) Error {
+    msg := def.Message
+    if msg == "" {
+        msg = err.Error()
+    }
+
+    return created(&GenericError{
+        Code:       def.Code,
+        Message:    msg,
+        errType:    def.Type,
+        stacktrace: NewStacktrace(skip),
+    }).WithCause(wrap(err, skip+1))
+}
+
+func wrapType(err error, errType, code string, skip int) Error {
+    return created(&GenericError{
+        Code:       code,
+        Message:    err.Error(),
+        errType:    errType,
+        stacktrace: NewStacktrace(skip),
+    }).WithCause(wrap(err, skip+1))
+}
+
+func wrapMessage(err error, msg string, skip int) Error {
+    if err == nil {
+        return nil
+    }
+
+    cause := wrap(err, skip+1)
+
+    return created(&GenericError{
         Code:       GenericCode,
+        Message:    msg,
+        errType:    cause.GetType(),
+        wrapped:    true,
+        stacktrace: NewStacktrace(skip),
+    }).WithCause(cause)
+}
+
+// wrap returns err as an Error, keeping err as the cause of the Error made
+// for it. The stacktrace starts skip frames above the caller.
+func wrap(err error, skip int) Error {
+    if err == nil {
+        return nil
+    }
+    if xerr, ok := err.(Error); ok {
+        return xerr
+    }
+
+    return created(adapt(err, NoneType, GenericCode, skip+1))
+}
+
+// asError returns err as an Error, wrapping it without notifying the
+// creation observers. The stacktrace starts at the caller's caller.
+func asError(err error) Error {
+    if xerr, ok := err.(Error); ok {
+        return xerr
+    }
+
+    return adapt(err, NoneType, GenericCode, 3)
+}
+
+// adapt makes an Error with the message of err that unwraps to err. The
+// stacktrace starts skip frames above adapt.
+func adapt(err error, errType, code string, skip int) *GenericError {
+    return &GenericError{
+        Code:       code,
         Message:    err.Error(),
-        stacktrace: NewStacktrace(1),
+        errType:    errType,
+        err:        err,
+        stacktrace: NewStacktrace(skip),
     }
 }
@@ -0,0 +1,100 @@
+package errors
+
+import (
+    "context"
+    "sync"
+)
+
+// A ContextExtractor returns a value carried by ctx, and whether it was found.
+type ContextExtractor func(ctx context.Context) (interface{}, bool)
+
+type contextField struct {
+    key     string
+    extract ContextExtractor
+}
+
+var (
+    contextFieldsMu sync.RWMutex
+    contextFields   []contextField
+)
+
+// RegisterContextField makes WithContext, and the NewCtx constructors, copy
+// the value found by extract onto the error as the field key.
+func RegisterContextField(key string, extract ContextExtractor) {
+    contextFieldsMu.Lock()
+    contextFields = append(contextFields, contextField{key: key, extract: extract})
+    contextFieldsMu.Unlock()
+}
+
+// ResetContextFields removes all registered context extractors.
+func ResetContextFields() {
+    contextFieldsMu.Lock()
+    contextFields = nil
+    contextFieldsMu.Unlock()
+}
+
+// WithContext records the trace and span active in ctx and the values of the
+// registered context fields on the error.
+func (e *GenericError) WithContext(ctx context.Context) Error {
+    if ctx == nil {
+        return e
+    }
+
+    traceExtractorMu.RLock()
+    extractTrace := traceExtractor
+    traceExtractorMu.RUnlock()
+
+    if extractTrace != nil {
+        e.traceID, e.spanID = extractTrace(ctx)
+    }
+
+    contextFieldsMu.RLock()
+    fields := contextFields
+    contextFieldsMu.RUnlock()
+
+    for _, field := range fields {
+        if value, ok := field.extract(ctx); ok {
+            e.WithField(field.key, value)
+        }
+    }
+
+    return e
+}
+
+func NewCtx(ctx context.Context, msg string) Error {
+    e := &GenericError{
+        Code:       GenericCode,
+        Message:    msg,
+        stacktrace: NewStacktrace(1),
+    }
+    e.WithContext(ctx)
+
+    return created(e)
+}
+
+func NewWithTypeAndCodeCtx(ctx context.Context, errType, code, msg string) Error {
+    e := &GenericError{
+        Code:       code,
+        Message:    msg,
+        errType:    errType,
+        stacktrace: NewStacktrace(1),
+    }
+    e.WithContext(ctx)
+
+    return created(e)
+}
+
+func (e *ErrorDefinition) NewCtx(ctx context.Context, msg ...string) Error {
+    xerr := &GenericError{
+        Code:       e.Code,
+        Message:    e.Message,
+        errType:    e.Type,
+        stacktrace: NewStacktrace(1),
+    }
+    if len(msg) > 0 {
+        xerr.Message = msg[0]
+    }
+    xerr.WithContext(ctx)
+
+    return created(xerr)
+}
@@ -0,0 +1,45 @@
+package errors
+
+import (
+    "context"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+type requestIDKey struct{}
+
+func TestNewCtx(t *testing.T) {
+    defer ResetContextFields()
+
+    RegisterContextField("request_id", func(ctx context.Context) (interface{}, bool) {
+        id, ok := ctx.Value(requestIDKey{}).(string)
+        return id, ok
+    })
+
+    ctx := context.WithValue(context.Background(), requestIDKey{}, "req1")
+
+    err := NewCtx(ctx, "err1")
+    require.Equal(t, Fields{"request_id": "req1"}, err.GetFields())
+    require.Contains(t, err.GetStacktrace().String(), "TestNewCtx")
+
+    err = DefNotFound("code1", "err1").NewCtx(ctx).WithField("tenant", "t1")
+    require.Equal(t, NotFoundType, err.GetType())
+    require.EqualError(t, err, "code1: err1")
+    require.Equal(t, Fields{"request_id": "req1", "tenant": "t1"}, err.GetFields())
+
+    err = NewWithTypeAndCodeCtx(context.Background(), BadRequestType, "code2", "err2")
+    require.Nil(t, err.GetFields())
+
+    result := fmt.Sprintf("%+v", DefNotFound("code1").NewCtx(ctx, "err1"))
+    require.Contains(t, result, "code1: err1\nrequest_id=req1\n")
+}
+
+func TestGetAllFields(t *testing.T) {
+    err1 := InternalError("code1", "err1").WithFields(Fields{"a": 1, "b": 1})
+    err2 := InternalError("code2", "err2").WithField("b", 2).WithCause(err1)
+
+    require.Equal(t, Fields{"a": 1, "b": 2}, err2.GetAllFields())
+    require.Equal(t, Fields{"b": 2}, ParseJSONError(err2.JSON()).GetFields())
+}
@@ -0,0 +1,83 @@
+package errors
+
+import "fmt"
+
+// Op is an operation name argument of E, such as "repo.load".
+type Op string
+
+// Code is an error code argument of E.
+type Code string
+
+// Type is an error type argument of E, such as Type(NotFoundType).
+type Type string
+
+// E builds an error from its arguments, given in any order:
+//
+//	Op                 the operation that failed
+//	Code               the error code
+//	Type               the error type
+//	string             the message
+//	*ErrorDefinition   the code, type and message of the definition
+//	Fields             fields of the error
+//	Severity           the severity of the error
+//	error              the cause
+//
+// Later arguments override earlier ones. Without a type, the error takes the
+// type of its cause. Without code and message, the error only adds its
+// operation to the chain:
+//
+//	return errors.E(errors.Op("repo.load"), err)
+//
+// Without arguments, E returns an InternalErrorType error saying so.
+func E(args ...interface{}) Error {
+    e := &GenericError{
+        Code:       GenericCode,
+        stacktrace: NewStacktrace(1),
+    }
+    if len(args) == 0 {
+        e.Message = "errors.E: no arguments"
+        e.errType = InternalErrorType
+    }
+
+    var cause error
+    for _, arg := range args {
+        switch arg := arg.(type) {
+        case Op:
+            e.op = string(arg)
+        case Code:
+            e.Code = string(arg)
+        case Type:
+            e.errType = string(arg)
+        case string:
+            e.Message = arg
+        case *ErrorDefinition:
+            e.Code = arg.Code
+            e.errType = arg.Type
+            if arg.Message != "" {
+                e.Message = arg.Message
+            }
+        case Fields:
+            e.WithFields(arg)
+        case Severity:
+            e.severity = arg
+        case error:
+            cause = arg
+        case nil:
+        default:
+            e.Message = fmt.Sprintf("errors.E: unknown argument %T, value %v", arg, arg)
+            e.errType = InternalErrorType
+        }
+    }
+
+    if cause == nil {
+        return created(e)
+    }
+
+    c := wrap(cause, 2)
+    if e.errType == NoneType {
+        e.errType = c.GetType()
+    }
+    e.wrapped = true
+
+    return created(e).WithCause(c)
+}
@@ -0,0 +1,51 @@
+package errors
+
+import (
+    stderrors "errors"
+    "fmt"
+    "io"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestE(t *testing.T) {
+    err := E(Op("db.query"), Type(NotFoundType), Code("e1"), "user not found", Fields{"id": 42})
+    require.EqualError(t, err, "db.query: e1: user not found")
+    require.Equal(t, NotFoundType, err.GetType())
+    require.Equal(t, "db.query", err.GetOp())
+    require.Equal(t, Fields{"id": 42}, err.GetFields())
+    require.Equal(t, "TestE", err.GetStacktrace()[len(err.GetStacktrace())-1].Function)
+
+    err = E(err, Op("repo.load"))
+    err = E(Op("svc.handler"), err, SeverityCritical)
+    require.EqualError(t, err, "svc.handler: repo.load: db.query: e1: user not found")
+    require.Equal(t, "svc.handler: repo.load: db.query: e1: user not found", fmt.Sprint(err))
+    require.Equal(t, NotFoundType, err.GetType())
+    require.Equal(t, SeverityCritical, err.GetSeverity())
+    require.Equal(t, 42, err.GetAllFields()["id"])
+
+    def := DefTimeout("e2", "upstream timed out")
+    err = E(Op("client.get"), def, io.ErrUnexpectedEOF)
+    require.EqualError(t, err, "client.get: e2: upstream timed out: unexpected EOF")
+    require.True(t, def.Is(err))
+    require.True(t, stderrors.Is(err, io.ErrUnexpectedEOF))
+    require.Equal(t, "client.get: e2: upstream timed out: unexpected EOF", fmt.Sprint(err))
+
+    err = E(Op("x"), 3.14)
+    require.Equal(t, InternalErrorType, err.GetType())
+    require.Contains(t, err.Error(), "unknown argument float64")
+
+    sentinel := NotFound("e4", "user not found")
+    require.True(t, Is(E(Code("e4"), "loading user", io.EOF), sentinel))
+    require.False(t, Is(E(Op("repo.load"), io.EOF), New("unrelated")))
+
+    err = E(Code("e3"), "NotFound")
+    require.EqualError(t, err, "e3: NotFound")
+    require.Equal(t, NoneType, err.GetType())
+
+    err = E()
+    require.EqualError(t, err, "errors.E: no arguments")
+    require.Equal(t, InternalErrorType, err.GetType())
+    require.Equal(t, "TestE", err.GetStacktrace()[len(err.GetStacktrace())-1].Function)
+}
@@ -41,14 +41,18 @@ func (g *Group) Wait() Error {
 // Go calls the given function in a new goroutine.
 //
 // The first call to return a non-nil error cancels the group; its error will be
-// returned by Wait.
+// returned by Wait, as a copy with the stack calling Go attached as its async
+// stacktrace. The copy is not the error f returned, so compare it with Is
+// rather than ==.
 func (g *Group) Go(f func() Error) {
     g.wg.Add(1)
+    spawn := NewStacktrace(1)
 
     go func() {
         defer g.wg.Done()
 
         if err := f(); err != nil {
+            err = withAsyncStack(err, spawn)
             g.errOnce.Do(func() {
                 g.err = err
                 if g.cancel != nil {
@@ -58,3 +62,39 @@ func (g *Group) Go(f func() Error) {
         }
     }()
 }
+
+// Go calls the given function in a new goroutine and delivers its result on
+// the returned channel. As with Group.Go, a non-nil error is delivered as a
+// copy with the stack calling Go attached as its async stacktrace.
+func Go(f func() Error) <-chan Error {
+    result := make(chan Error, 1)
+    spawn := NewStacktrace(1)
+
+    go func() {
+        err := f()
+        if err != nil {
+            err = withAsyncStack(err, spawn)
+        }
+        result <- err
+        close(result)
+    }()
+
+    return result
+}
+
+// withAsyncStack returns a copy of err with the spawn stack attached. err
+// itself is left untouched, as it may be shared, e.g. a sentinel returned by
+// several goroutines.
+func withAsyncStack(err Error, spawn Stacktrace) Error {
+    e, ok := err.(*GenericError)
+    if !ok || e.asyncStack != nil {
+        return err
+    }
+
+    withStack := *e
+    withStack.asyncStack = spawn
+    withStack.fields = nil
+    withStack.WithFields(e.fields)
+
+    return &withStack
+}
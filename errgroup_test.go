@@ -0,0 +1,75 @@
+package errors
+
+import (
+    stderrors "errors"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func launch(g *Group) {
+    g.Go(func() Error {
+        return InternalError("code1", "err1")
+    })
+}
+
+func TestGroupAsyncStack(t *testing.T) {
+    g := &Group{}
+    launch(g)
+    err := g.Wait()
+
+    require.NotNil(t, err)
+    require.Equal(t, "func1", err.GetStacktrace().Caller().Function)
+    require.Equal(t, "launch", err.GetAsyncStacktrace().Caller().Function)
+    require.Equal(t, "TestGroupAsyncStack", err.GetAsyncStacktrace().At(len(err.GetAsyncStacktrace())-2).Function)
+
+    result := fmt.Sprintf("%+v", err)
+    require.Contains(t, result, "--- async boundary ---\n")
+    require.Contains(t, result, "launch\t")
+
+    newErr := ParseJSONError(err.JSON())
+    require.True(t, err.GetAsyncStacktrace().Equal(newErr.GetAsyncStacktrace()))
+
+    b, _ := err.(*GenericError).MarshalBinary()
+    decoded := &GenericError{}
+    require.NoError(t, decoded.UnmarshalBinary(b))
+    require.True(t, err.GetAsyncStacktrace().Equal(decoded.GetAsyncStacktrace()))
+}
+
+func TestGo(t *testing.T) {
+    err := <-Go(func() Error { return NotFound("code1", "err1") })
+    require.EqualError(t, err, "code1: err1")
+    require.Equal(t, "TestGo", err.GetAsyncStacktrace().Caller().Function)
+
+    require.Nil(t, <-Go(func() Error { return nil }))
+
+    inner := NotFound("code1", "err1")
+    g := &Group{}
+    g.Go(func() Error { return <-Go(func() Error { return inner }) })
+    require.Equal(t, "func3", g.Wait().GetAsyncStacktrace().Caller().Function)
+}
+
+func TestGroupSharedError(t *testing.T) {
+    sentinel := NotFound("code1", "err1")
+
+    g := &Group{}
+    for i := 0; i < 10; i++ {
+        g.Go(func() Error { return sentinel })
+    }
+    err := g.Wait()
+    chErr := <-Go(func() Error { return sentinel })
+
+    require.Nil(t, sentinel.GetAsyncStacktrace())
+    require.NotNil(t, err.GetAsyncStacktrace())
+    require.NotNil(t, chErr.GetAsyncStacktrace())
+    require.True(t, err != sentinel)
+    require.True(t, Is(err, sentinel))
+    require.True(t, stderrors.Is(chErr, sentinel))
+
+    withField := NotFound("code2", "err2").WithField("id", 1)
+    chErr = <-Go(func() Error { return withField })
+    chErr.WithField("id", 2)
+    require.Equal(t, 1, withField.GetFields()["id"])
+    require.Equal(t, 2, chErr.GetFields()["id"])
+}
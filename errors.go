@@ -1,8 +1,12 @@
 package errors
 
 import (
+    "context"
+    "encoding/json"
+    stderrors "errors"
     "fmt"
     "io"
+    "strings"
 )
 
 const GenericCode = "Generic"
@@ -16,6 +20,7 @@ const (
     TimeoutType       = "Timeout"
     InternalErrorType = "InternalError"
     NotImplementType  = "NotImplement"
+    ConflictType      = "Conflict"
 )
 
 type Error interface {
@@ -27,33 +32,78 @@ type Error interface {
     Format(s fmt.State, verb rune)
     RootError() Error
     IsPanic() bool
+    IsRemote() bool
     JSON() *JSONError
 
     GetCode() string
+    GetOp() string
     GetMessage() string
     GetStacktrace() Stacktrace
+    GetAsyncStacktrace() Stacktrace
     GetAllInputs() []interface{}
     GetInput() interface{}
+    GetFields() Fields
+    GetAllFields() Fields
+    GetViolations() []*Violation
     GetType() string
+    GetSeverity() Severity
+    GetTraceID() string
+    GetSpanID() string
+    GetOrigin() string
+    GetReceivedAt() *StacktraceFrame
 
     WithPanic() Error
+    WithSeverity(severity Severity) Error
     WithCause(err error) Error
     WithInput(input interface{}) Error
+    WithField(key string, value interface{}) Error
+    WithFields(fields Fields) Error
     WithMessage(msg string) Error
     WithMessagef(format string, args ...interface{}) Error
+    WithOp(op string) Error
+    WithContext(ctx context.Context) Error
 }
 
+// Fields are key-value pairs describing the circumstances of an error, such
+// as the request ID or tenant.
+type Fields map[string]interface{}
+
 type GenericError struct {
     Code       string `json:"code,omitempty"`
     Message    string `json:"message,omitempty"`
     errType    string
+    op         string
+    wrapped    bool
     cause      Error
+    err        error
     stacktrace Stacktrace
+    asyncStack Stacktrace
     panic      bool
+    severity   Severity
     input      interface{}
+    fields     Fields
+    violations []*Violation
+    traceID    string
+    spanID     string
+    extra      map[string]json.RawMessage
+    remote     bool
+    origin     string
+    receivedAt *StacktraceFrame
 }
 
+// Error returns the operation, code and message of the error. An error made
+// by Wrap, Wrapf, Annotate or E with a cause reads as those followed by the
+// same of each of its causes, in chain order.
 func (e *GenericError) Error() string {
+    if e.wrapped {
+        return causesString(e)
+    }
+
+    return layerString(e)
+}
+
+// text is the code and message of the error alone.
+func (e *GenericError) text() string {
     if e.Code != "" && e.Code != GenericCode {
         return fmt.Sprintf("%s: %s", e.Code, e.Message)
     }
@@ -62,66 +112,82 @@ func (e *GenericError) Error() string {
 }
 
 func (e *GenericError) ErrorWithCause() string {
-    s := fmt.Sprintf("%+v\n", e.Error())
-    cause := e.cause
-    for cause != nil {
-        s += fmt.Sprintf("%+v\n", cause.Error())
-        xcause := cause.Unwrap()
-        if xcause == nil {
-            break
-        }
-        cause = xcause.(Error)
-    }
+    sb := &strings.Builder{}
+    currentFormatter().FormatCauses(sb, e)
 
-    return s
+    return sb.String()
 }
 
+// Format implements fmt.Formatter:
+//
+//	%v, %s   the message of the error and its causes, joined by ": "
+//	%+v      the error in full, laid out by the Formatter set with SetFormatter
+//	%#v      a Go-syntax representation, as returned by GoString
+//	%#+v     the error in full, laid out by TreeFormatter
+//	%q       the %v output as a double-quoted string
+//	%x, %X   the %v output in hexadecimal
 func (e *GenericError) Format(s fmt.State, verb rune) {
     switch verb {
     case 'v':
-        _, _ = fmt.Fprintf(s, "%s\n", e.Error())
-
-        if s.Flag('+') {
-            if e.stacktrace != nil {
-                for _, frame := range e.stacktrace {
-                    _, _ = fmt.Fprintf(s, "%s\t%s:%d\n", frame.Function, frame.Filename, frame.Lineno)
-                }
-            }
-
-            cause := e.cause
-            for cause != nil {
-                _, _ = fmt.Fprintf(s, "\n%s\n", cause.Error())
-                if stack := cause.GetStacktrace(); stack != nil {
-                    for _, frame := range stack {
-                        _, _ = fmt.Fprintf(s, "%s\t%s:%d\n", frame.Function, frame.Filename, frame.Lineno)
-                    }
-                }
-
-                xcause := cause.Unwrap()
-                if xcause == nil {
-                    break
-                }
-                cause = xcause.(Error)
-            }
+        switch {
+        case s.Flag('+') && s.Flag('#'):
+            TreeFormatter.FormatError(s, e)
+        case s.Flag('+'):
+            currentFormatter().FormatError(s, e)
+        case s.Flag('#'):
+            _, _ = io.WriteString(s, e.GoString())
+        default:
+            _, _ = io.WriteString(s, causesString(e))
         }
     case 's':
-        _, _ = io.WriteString(s, e.Error())
-        cause := e.cause
-        for cause != nil {
-            _, _ = fmt.Fprintf(s, " %s", cause.Error())
-            xcause := cause.Unwrap()
-            if xcause == nil {
-                break
-            }
-            cause = xcause.(Error)
-        }
+        _, _ = io.WriteString(s, causesString(e))
+    case 'q':
+        _, _ = fmt.Fprintf(s, "%q", causesString(e))
+    case 'x':
+        _, _ = fmt.Fprintf(s, "%x", causesString(e))
+    case 'X':
+        _, _ = fmt.Fprintf(s, "%X", causesString(e))
     default:
-        _, _ = fmt.Fprintf(s, "%s", e.Error())
+        _, _ = fmt.Fprintf(s, "%%!%c(%T=%s)", verb, e, causesString(e))
     }
 }
 
+// GoString returns a Go-syntax representation of the error chain, with its
+// type, panic flag, input and fields.
+func (e *GenericError) GoString() string {
+    sb := &strings.Builder{}
+    _, _ = fmt.Fprintf(sb, "&errors.GenericError{Code:%q, Message:%q, Type:%q", e.Code, e.Message, e.errType)
+    if e.op != "" {
+        _, _ = fmt.Fprintf(sb, ", Op:%q", e.op)
+    }
+    if e.panic {
+        sb.WriteString(", Panic:true")
+    }
+    if e.input != nil {
+        _, _ = fmt.Fprintf(sb, ", Input:%#v", e.input)
+    }
+    if len(e.fields) > 0 {
+        _, _ = fmt.Fprintf(sb, ", Fields:%#v", e.fields)
+    }
+    if e.cause != nil {
+        _, _ = fmt.Fprintf(sb, ", Cause:%#v", e.cause)
+    }
+    sb.WriteString("}")
+
+    return sb.String()
+}
+
+// Unwrap returns the cause of the error. An error made from an error that is
+// not an Error, by WithCause or Wrap, unwraps to that original error.
 func (e *GenericError) Unwrap() error {
-    return e.cause
+    if e.cause != nil {
+        return e.cause
+    }
+    if e.err != nil {
+        return e.err
+    }
+
+    return nil
 }
 
 func (e *GenericError) WithCause(err error) Error {
@@ -135,6 +201,7 @@ func (e *GenericError) WithCause(err error) Error {
             Code:       GenericCode,
             Message:    err.Error(),
             errType:    InternalErrorType,
+            err:        err,
             stacktrace: NewStacktrace(1),
         }
 
@@ -142,7 +209,9 @@ func (e *GenericError) WithCause(err error) Error {
     }
 
     e.cause = cause
-    e.stacktrace = e.stacktrace[len(e.stacktrace)-1:]
+    if len(e.stacktrace) > 0 {
+        e.stacktrace = e.stacktrace[len(e.stacktrace)-1:]
+    }
     e.panic = cause.panic
 
     return e
@@ -160,6 +229,23 @@ func (e *GenericError) WithInput(input interface{}) Error {
     return e
 }
 
+func (e *GenericError) WithField(key string, value interface{}) Error {
+    if e.fields == nil {
+        e.fields = make(Fields)
+    }
+    e.fields[key] = value
+
+    return e
+}
+
+func (e *GenericError) WithFields(fields Fields) Error {
+    for key, value := range fields {
+        e.WithField(key, value)
+    }
+
+    return e
+}
+
 func (e *GenericError) WithMessage(msg string) Error {
     e.Message = msg
 
@@ -180,23 +266,18 @@ func (e *GenericError) GetStacktrace() Stacktrace {
     return e.stacktrace
 }
 
+// GetAsyncStacktrace returns the stack of the goroutine that launched the one
+// the error was returned in, when the error went through Group.Go or Go.
+func (e *GenericError) GetAsyncStacktrace() Stacktrace {
+    return e.asyncStack
+}
+
 func (e *GenericError) GetAllInputs() []interface{} {
     inputs := make([]interface{}, 0, 5)
-    if e.input != nil {
-        inputs = append(inputs, e.input)
-    }
-
-    cause := e.cause
-    for cause != nil {
-        cerr := cause.(*GenericError)
-        if cerr.input != nil {
-            inputs = append(inputs, cerr.input)
-        }
-        xcause := cerr.Unwrap()
-        if xcause == nil {
-            break
+    for _, cerr := range chain(e) {
+        if input := cerr.GetInput(); input != nil {
+            inputs = append(inputs, input)
         }
-        cause = xcause.(Error)
     }
 
     return inputs
@@ -206,6 +287,25 @@ func (e *GenericError) GetInput() interface{} {
     return e.input
 }
 
+func (e *GenericError) GetFields() Fields {
+    return e.fields
+}
+
+// GetAllFields merges the fields of the error and its causes. Fields of outer
+// errors take precedence over the same keys on their causes.
+func (e *GenericError) GetAllFields() Fields {
+    fields := make(Fields)
+    for _, cerr := range chain(e) {
+        for key, value := range cerr.GetFields() {
+            if _, ok := fields[key]; !ok {
+                fields[key] = value
+            }
+        }
+    }
+
+    return fields
+}
+
 func (e *GenericError) GetMessage() string {
     return e.Message
 }
@@ -214,64 +314,56 @@ func (e *GenericError) GetType() string {
     return e.errType
 }
 
+// Is tells if the error or one of its causes is err or has its code. The
+// GenericCode layers added by Wrap, Wrapf, Annotate and E only match
+// themselves, and other GenericCode errors, whose code tells nothing about
+// them, must have the message of err too. An err that is not an Error matches
+// the original error the chain was made from.
 func (e *GenericError) Is(err error) bool {
+    errs := chain(e)
+
     xerr, ok := err.(Error)
     if !ok {
-        return false
-    }
+        root, ok := errs[len(errs)-1].(*GenericError)
 
-    if e.Code == xerr.GetCode() {
-        return true
+        return ok && root.err != nil && stderrors.Is(root.err, err)
     }
 
-    cause := e.cause
-    for cause != nil {
-        cerr := cause.(*GenericError)
-        if cerr.Code == xerr.GetCode() {
+    for _, cerr := range errs {
+        if cerr == xerr {
+            return true
+        }
+        if cerr.GetCode() != xerr.GetCode() {
+            continue
+        }
+        if cerr.GetCode() != GenericCode {
             return true
         }
-        xcause := cerr.Unwrap()
-        if xcause == nil {
-            break
+        if ge, ok := cerr.(*GenericError); ok && ge.wrapped {
+            continue
+        }
+        if cerr.GetMessage() == xerr.GetMessage() {
+            return true
         }
-        cause = xcause.(*GenericError)
     }
 
     return false
 }
 
 func (e *GenericError) IsType(errType string) bool {
-    if e.errType == errType {
-        return true
-    }
-
-    cause := e.cause
-    for cause != nil {
-        cerr := cause.(*GenericError)
-        if cerr.errType == cerr.errType {
+    for _, cerr := range chain(e) {
+        if cerr.GetType() == errType {
             return true
         }
-        xcause := cerr.Unwrap()
-        if xcause == nil {
-            return false
-        }
-        cause = xcause.(Error)
     }
 
     return false
 }
 
 func (e *GenericError) RootError() Error {
-    cause := e.cause
-    for cause != nil {
-        xcause := cause.Unwrap()
-        if xcause == nil {
-            return cause
-        }
-        cause = xcause.(Error)
-    }
+    errs := chain(e)
 
-    return e
+    return errs[len(errs)-1]
 }
 
 func (e *GenericError) IsPanic() bool {
@@ -279,89 +371,98 @@ func (e *GenericError) IsPanic() bool {
 }
 
 func New(msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       GenericCode,
         Message:    msg,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func NewWithCode(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func NewWithTypeAndCode(errType, code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    errType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func BadRequest(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    BadRequestType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func Unauthorized(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    UnauthorizedType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func Forbidden(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    ForbiddenType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func NotFound(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    NotFoundType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func Timeout(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    TimeoutType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func InternalError(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    InternalErrorType,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func NotImplement(code, msg string) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       code,
         Message:    msg,
         errType:    NotImplementType,
         stacktrace: NewStacktrace(1),
-    }
+    })
+}
+
+func Conflict(code, msg string) Error {
+    return created(&GenericError{
+        Code:       code,
+        Message:    msg,
+        errType:    ConflictType,
+        stacktrace: NewStacktrace(1),
+    })
 }
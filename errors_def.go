@@ -1,6 +1,9 @@
 package errors
 
-import "fmt"
+import (
+    "fmt"
+    "sync"
+)
 
 type ErrorDefinition struct {
     Code    string
@@ -8,6 +11,38 @@ type ErrorDefinition struct {
     Message string
 }
 
+var (
+    definitionsMu sync.RWMutex
+    definitions   = make(map[string]*ErrorDefinition)
+)
+
+// Register makes the definitions known to the decoders: a decoded error
+// without a type takes the type, and the message if it has none, of the
+// definition registered for its code. A definition replaces any registered
+// before with the same code.
+func Register(defs ...*ErrorDefinition) {
+    definitionsMu.Lock()
+    for _, def := range defs {
+        definitions[def.Code] = def
+    }
+    definitionsMu.Unlock()
+}
+
+// ResetDefinitions removes the definitions added by Register.
+func ResetDefinitions() {
+    definitionsMu.Lock()
+    definitions = make(map[string]*ErrorDefinition)
+    definitionsMu.Unlock()
+}
+
+// LookupDef returns the definition registered for code, or nil.
+func LookupDef(code string) *ErrorDefinition {
+    definitionsMu.RLock()
+    defer definitionsMu.RUnlock()
+
+    return definitions[code]
+}
+
 func Def(errType, code string, msg ...string) *ErrorDefinition {
     e := &ErrorDefinition{
         Code: code,
@@ -23,33 +58,43 @@ func Def(errType, code string, msg ...string) *ErrorDefinition {
 
 func (e *ErrorDefinition) New(msg ...string) Error {
     if len(msg) > 0 {
-        return &GenericError{
+        return created(&GenericError{
             Code:       e.Code,
             Message:    msg[0],
             errType:    e.Type,
             stacktrace: NewStacktrace(1),
-        }
+        })
     }
 
-    return &GenericError{
+    return created(&GenericError{
         Code:       e.Code,
         Message:    e.Message,
         errType:    e.Type,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
 func (e *ErrorDefinition) Newf(format string, v ...interface{}) Error {
-    return &GenericError{
+    return created(&GenericError{
         Code:       e.Code,
         Message:    fmt.Sprintf(format, v...),
         errType:    e.Type,
         stacktrace: NewStacktrace(1),
-    }
+    })
 }
 
+// Is tells if err or one of its causes was created from the definition.
 func (e *ErrorDefinition) Is(err Error) bool {
-    return err != nil && e.Code == err.GetCode()
+    if err == nil {
+        return false
+    }
+    for _, cerr := range chain(err) {
+        if cerr.GetCode() == e.Code {
+            return true
+        }
+    }
+
+    return false
 }
 
 func DefBadRequest(code string, msg ...string) *ErrorDefinition {
@@ -135,3 +180,15 @@ func DefNotImplement(code string, msg ...string) *ErrorDefinition {
 
     return e
 }
+
+func DefConflict(code string, msg ...string) *ErrorDefinition {
+    e := &ErrorDefinition{
+        Code: code,
+        Type: ConflictType,
+    }
+    if len(msg) > 0 {
+        e.Message = msg[0]
+    }
+
+    return e
+}
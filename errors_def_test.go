@@ -26,5 +26,13 @@ func TestHttpStatus(t *testing.T) {
     require.Equal(t, 403, HttpStatus(DefForbidden("code1", "err1").New().GetType()))
     require.Equal(t, 501, HttpStatus(DefNotImplement("code1", "err1").New().GetType()))
     require.Equal(t, 441, HttpStatus(DefTimeout("code1", "err1").New().GetType()))
+    require.Equal(t, 409, HttpStatus(DefConflict("code1", "err1").New().GetType()))
     require.Equal(t, 401, HttpStatus(DefUnauthorized("code1", "err1").New().GetType()))
 }
+
+func TestHttpStatusType(t *testing.T) {
+    for _, errType := range []string{BadRequestType, UnauthorizedType, ForbiddenType, NotFoundType, TimeoutType, InternalErrorType, NotImplementType, ConflictType} {
+        require.Equal(t, errType, HttpStatusType(HttpStatus(errType)))
+    }
+    require.Equal(t, NoneType, HttpStatusType(418))
+}
@@ -74,7 +74,10 @@ func TestUtils(t *testing.T) {
     require.True(t, err1.(Error).Is(err2))
     require.True(t, err1.(Error).Is(err2.(Error).Unwrap()))
     require.True(t, err1.(Error).Is(err2.(Error).Unwrap().(Error).Unwrap()))
-    require.Nil(t, err2.(Error).Unwrap().(Error).Unwrap().(Error).Unwrap())
+    root := err2.(Error).Unwrap().(Error).Unwrap().(Error).Unwrap()
+    require.EqualError(t, root, "err0")
+    _, ok := root.(Error)
+    require.False(t, ok)
     require.True(t, Is(err1, err2))
 
     err0 := Unwrap(err1)
@@ -83,7 +86,7 @@ func TestUtils(t *testing.T) {
     require.EqualError(t, err0, "err0")
 
     wrapErr0 := Wrap(err0)
-    _, ok := wrapErr0.(Error)
+    _, ok = wrapErr0.(Error)
     require.True(t, ok)
 }
 
@@ -120,3 +123,69 @@ func TestJSON(t *testing.T) {
     require.Equal(t, x().(Error).RootError().Error(), newErr.RootError().Error())
     require.Equal(t, x().(Error).Unwrap().Error(), newErr.Unwrap().Error())
 }
+
+func TestMarshalJSON(t *testing.T) {
+    defer SetJSONVerbosity(JSONFull)
+
+    type job struct {
+        ID  string        `json:"id"`
+        Err *GenericError `json:"err"`
+    }
+
+    err := x().(Error).WithInput(1).WithField("tenant", "t1")
+    b, jerr := json.Marshal(&job{ID: "job1", Err: err.(*GenericError)})
+    require.NoError(t, jerr)
+
+    decoded := &job{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+    require.Equal(t, "job1", decoded.ID)
+    require.Equal(t, err.Error(), decoded.Err.Error())
+    require.Equal(t, InternalErrorType, decoded.Err.GetType())
+    require.Equal(t, float64(1), decoded.Err.GetInput())
+    require.Equal(t, Fields{"tenant": "t1"}, decoded.Err.GetFields())
+    require.Equal(t, err.GetStacktrace(), decoded.Err.GetStacktrace())
+    require.EqualError(t, decoded.Err.RootError(), "err0")
+
+    SetJSONVerbosity(JSONNoStacktrace)
+    b, jerr = json.Marshal(err)
+    require.NoError(t, jerr)
+    require.NotContains(t, string(b), "stacktrace")
+    require.Contains(t, string(b), `"tenant":"t1"`)
+
+    SetJSONVerbosity(JSONCompact)
+    b, jerr = json.Marshal(err)
+    require.NoError(t, jerr)
+    require.JSONEq(t, `{
+        "v": 1,
+        "code": "code2",
+        "message": "err2",
+        "errType": "InternalError",
+        "cause": {
+            "code": "code1",
+            "message": "err1",
+            "errType": "InternalError",
+            "cause": {"code": "Generic", "message": "err0", "errType": "InternalError"}
+        }
+    }`, string(b))
+}
+
+func TestWithCauseWithoutStacktrace(t *testing.T) {
+    binaryErr := &GenericError{}
+    b, err := (&GenericError{Code: "code1", Message: "err1"}).MarshalBinary()
+    require.NoError(t, err)
+    require.NoError(t, binaryErr.UnmarshalBinary(b))
+
+    for name, decoded := range map[string]Error{
+        "rpc":      ParseRPCError(&RPCError{Code: RPCNotFound, Message: "err1"}),
+        "graphql":  ParseGraphQLError(&GraphQLError{Message: "err1"}),
+        "problem":  FromProblem(&Problem{Title: "err1", Status: 404}),
+        "binary":   binaryErr,
+        "json":     ParseJSONError(NotFound("code1", "err1").JSON().WithoutStacktrace()),
+        "compact":  ParseJSONError(NotFound("code1", "err1").JSON().Compact()),
+        "no cause": ParseJSONError(&JSONError{Code: "code1", Message: "err1"}),
+    } {
+        require.Empty(t, decoded.GetStacktrace(), name)
+        require.NotPanics(t, func() { decoded.WithCause(New("err0")) }, name)
+        require.EqualError(t, decoded.RootError(), "err0", name)
+    }
+}
@@ -0,0 +1,232 @@
+// Package errorstest provides test assertions on errors.Error values.
+//
+// The assertions report failures with t.Errorf and return whether they
+// passed, so that a test can go on or stop as it sees fit.
+package errorstest
+
+import (
+    "bytes"
+    "encoding/json"
+    "flag"
+    "io/ioutil"
+    "os"
+    "path/filepath"
+    "reflect"
+    "strings"
+    "testing"
+
+    "github.com/onedaycat/errors"
+)
+
+var update = flag.Bool("errorstest.update", false, "rewrite the golden files of AssertGoldenJSON")
+
+func asError(t testing.TB, err error) (errors.Error, bool) {
+    t.Helper()
+
+    if err == nil {
+        t.Errorf("expected an error, got nil")
+        return nil, false
+    }
+    xerr, ok := err.(errors.Error)
+    if !ok {
+        t.Errorf("expected an errors.Error, got %T: %v", err, err)
+        return nil, false
+    }
+
+    return xerr, true
+}
+
+// AssertCode asserts that err is an Error with the given code.
+func AssertCode(t testing.TB, err error, code string) bool {
+    t.Helper()
+
+    xerr, ok := asError(t, err)
+    if !ok {
+        return false
+    }
+    if xerr.GetCode() != code {
+        t.Errorf("expected code %q, got %q: %v", code, xerr.GetCode(), xerr)
+        return false
+    }
+
+    return true
+}
+
+// AssertType asserts that err is an Error of the given type.
+func AssertType(t testing.TB, err error, errType string) bool {
+    t.Helper()
+
+    xerr, ok := asError(t, err)
+    if !ok {
+        return false
+    }
+    if xerr.GetType() != errType {
+        t.Errorf("expected type %q, got %q: %v", errType, xerr.GetType(), xerr)
+        return false
+    }
+
+    return true
+}
+
+// AssertIs asserts that err or one of its causes was created from def.
+func AssertIs(t testing.TB, err error, def *errors.ErrorDefinition) bool {
+    t.Helper()
+
+    xerr, ok := asError(t, err)
+    if !ok {
+        return false
+    }
+    if !def.Is(xerr) {
+        t.Errorf("expected an error of definition %q, got %v", def.Code, xerr)
+        return false
+    }
+
+    return true
+}
+
+// AssertCauseChain asserts the codes of err and its causes, outermost first.
+// The chain ends at the first cause that is not an Error.
+func AssertCauseChain(t testing.TB, err error, codes ...string) bool {
+    t.Helper()
+
+    xerr, ok := asError(t, err)
+    if !ok {
+        return false
+    }
+
+    var got []string
+    for xerr != nil {
+        got = append(got, xerr.GetCode())
+        xerr, _ = xerr.Unwrap().(errors.Error)
+    }
+    if !reflect.DeepEqual(got, codes) {
+        t.Errorf("expected cause chain [%s], got [%s]", strings.Join(codes, " "), strings.Join(got, " "))
+        return false
+    }
+
+    return true
+}
+
+// AssertHasField asserts that err or one of its causes has the field key and,
+// if given, that the field has the value.
+func AssertHasField(t testing.TB, err error, key string, value ...interface{}) bool {
+    t.Helper()
+
+    xerr, ok := asError(t, err)
+    if !ok {
+        return false
+    }
+
+    got, ok := xerr.GetAllFields()[key]
+    if !ok {
+        t.Errorf("expected field %q, got %v", key, xerr.GetAllFields())
+        return false
+    }
+    if len(value) > 0 && !reflect.DeepEqual(got, value[0]) {
+        t.Errorf("expected field %q to be %#v, got %#v", key, value[0], got)
+        return false
+    }
+
+    return true
+}
+
+// AssertGoldenJSON compares the JSON of err, indented and with normalized
+// stacks, to the golden file at path. The golden files are rewritten when the
+// tests run with -errorstest.update.
+func AssertGoldenJSON(t testing.TB, err error, path string) bool {
+    t.Helper()
+
+    xerr, ok := asError(t, err)
+    if !ok {
+        return false
+    }
+
+    got, merr := json.MarshalIndent(Normalize(xerr.JSON()), "", "  ")
+    if merr != nil {
+        t.Errorf("marshalling %v: %v", xerr, merr)
+        return false
+    }
+    got = append(got, '\n')
+
+    if *update {
+        if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+            t.Errorf("updating golden file: %v", err)
+            return false
+        }
+        if err := ioutil.WriteFile(path, got, 0644); err != nil {
+            t.Errorf("updating golden file: %v", err)
+            return false
+        }
+    }
+
+    want, rerr := ioutil.ReadFile(path)
+    if rerr != nil {
+        t.Errorf("reading golden file: %v", rerr)
+        return false
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("JSON of %v does not match %s\ngot:\n%s\nwant:\n%s", xerr, path, got, want)
+        return false
+    }
+
+    return true
+}
+
+// Normalize returns a copy of the error chain whose stack frames keep only
+// the base name of their file and no line number, so that it does not change
+// with the place the code is checked out at or with unrelated edits. Frames
+// of the standard library, such as those of the test runner, are dropped as
+// they change with the Go version.
+func Normalize(jsonErr *errors.JSONError) *errors.JSONError {
+    if jsonErr == nil {
+        return nil
+    }
+
+    normalized := *jsonErr
+    normalized.Stacktrace = normalizeStack(jsonErr.Stacktrace)
+    normalized.AsyncStack = normalizeStack(jsonErr.AsyncStack)
+    normalized.Cause = Normalize(jsonErr.Cause)
+
+    return &normalized
+}
+
+func normalizeStack(stack []*errors.StacktraceFrame) []*errors.StacktraceFrame {
+    if stack == nil {
+        return nil
+    }
+
+    inApp := errors.Stacktrace(stack).InApp()
+    normalized := make([]*errors.StacktraceFrame, len(inApp))
+    for i, frame := range inApp {
+        normalized[i] = &errors.StacktraceFrame{
+            Filename: filepath.Base(frame.Filename),
+            Function: frame.Function,
+            Module:   frame.Module,
+        }
+    }
+
+    return normalized
+}
+
+// FakeFrame is the only frame of the stacks captured under FakeStacks.
+var FakeFrame = &errors.StacktraceFrame{
+    Filename: "fake.go",
+    Function: "fake",
+    Module:   "github.com/onedaycat/errors/errorstest",
+    Lineno:   1,
+}
+
+// FakeStacks makes the errors created until the end of the test carry a stack
+// of FakeFrame alone, for output that does not depend on the test code. It
+// changes the stacks of every goroutine, so tests using it must not run in
+// parallel.
+func FakeStacks(t testing.TB) {
+    errors.SetStacktraceFunc(func(int) errors.Stacktrace {
+        frame := *FakeFrame
+
+        return errors.Stacktrace{&frame}
+    })
+    t.Cleanup(func() {
+        errors.SetStacktraceFunc(nil)
+    })
+}
@@ -0,0 +1,92 @@
+package errorstest
+
+import (
+    "fmt"
+    "os"
+    "path/filepath"
+    "testing"
+
+    "github.com/onedaycat/errors"
+    "github.com/stretchr/testify/require"
+)
+
+// recorder is a testing.TB keeping the failures instead of reporting them.
+type recorder struct {
+    testing.TB
+    failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+    r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+var errUser = errors.DefNotFound("errorstest1", "user not found")
+
+func loadUser() error {
+    return errors.Wrap(errUser.New().WithField("id", 42), "loading user")
+}
+
+func TestAssertions(t *testing.T) {
+    err := loadUser()
+
+    require.True(t, AssertCode(t, err, errors.GenericCode))
+    require.True(t, AssertType(t, err, errors.NotFoundType))
+    require.True(t, AssertIs(t, err, errUser))
+    require.True(t, AssertCauseChain(t, err, errors.GenericCode, "errorstest1"))
+    require.True(t, AssertHasField(t, err, "id"))
+    require.True(t, AssertHasField(t, err, "id", 42))
+
+    r := &recorder{TB: t}
+    require.False(t, AssertCode(r, err, "errorstest1"))
+    require.False(t, AssertType(r, err, errors.BadRequestType))
+    require.False(t, AssertIs(r, err, errors.DefNotFound("errorstest2")))
+    require.False(t, AssertCauseChain(r, err, "errorstest1"))
+    require.False(t, AssertHasField(r, err, "name"))
+    require.False(t, AssertHasField(r, err, "id", "42"))
+    require.False(t, AssertCode(r, nil, "errorstest1"))
+    require.False(t, AssertCode(r, os.ErrNotExist, "errorstest1"))
+    require.Equal(t, []string{
+        `expected code "errorstest1", got "Generic": loading user: errorstest1: user not found`,
+        `expected type "BadRequest", got "NotFound": loading user: errorstest1: user not found`,
+        `expected an error of definition "errorstest2", got loading user: errorstest1: user not found`,
+        `expected cause chain [errorstest1], got [Generic errorstest1]`,
+        `expected field "name", got map[id:42]`,
+        `expected field "id" to be "42", got 42`,
+        `expected an error, got nil`,
+        `expected an errors.Error, got *errors.errorString: file does not exist`,
+    }, r.failures)
+}
+
+func TestAssertGoldenJSON(t *testing.T) {
+    err := loadUser()
+    require.True(t, AssertGoldenJSON(t, err, filepath.Join("testdata", "wrap.golden")))
+
+    if !*update {
+        r := &recorder{TB: t}
+        require.False(t, AssertGoldenJSON(r, errors.NotFound("code1", "err1"), filepath.Join("testdata", "wrap.golden")))
+        require.False(t, AssertGoldenJSON(r, err, filepath.Join("testdata", "missing.golden")))
+        require.Len(t, r.failures, 2)
+    }
+
+    normalized := Normalize(err.(errors.Error).JSON())
+    require.Zero(t, normalized.Stacktrace[0].Lineno)
+    require.NotZero(t, err.(errors.Error).GetStacktrace()[0].Lineno)
+
+    cause := err.(errors.Error).Unwrap().(errors.Error)
+    require.Equal(t, "tRunner", cause.GetStacktrace()[0].Function)
+    require.Equal(t, "TestAssertGoldenJSON", normalized.Cause.Stacktrace[0].Function)
+}
+
+func TestFakeStacks(t *testing.T) {
+    t.Run("fake", func(t *testing.T) {
+        FakeStacks(t)
+
+        err := errors.InternalError("code1", "err1")
+        require.Equal(t, errors.Stacktrace{FakeFrame}, err.GetStacktrace())
+        require.Equal(t, "code1: err1\nfake\tfake.go:1\n", fmt.Sprintf("%+v", err))
+    })
+
+    require.NotEqual(t, "fake", errors.InternalError("code1", "err1").GetStacktrace().Caller().Function)
+}
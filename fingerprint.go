@@ -0,0 +1,181 @@
+package errors
+
+import (
+    "crypto/sha1"
+    "encoding/hex"
+    "io"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// A FingerprintOption changes what Fingerprint takes into account.
+type FingerprintOption int
+
+const (
+    // IgnoreLines leaves line numbers out of the fingerprint, so the group of
+    // an error survives edits that only shift code around.
+    IgnoreLines FingerprintOption = iota + 1
+)
+
+// isInApp reports whether frame belongs to application code rather than the
+// standard library, whose import paths have no dot in their first element.
+func isInApp(frame *StacktraceFrame) bool {
+    module := frame.Module
+    if i := strings.Index(module, "/"); i >= 0 {
+        module = module[:i]
+    }
+
+    return strings.Contains(module, ".") || module == "main"
+}
+
+// Fingerprint returns a stable hash grouping occurrences of the same error. It
+// covers the code, type and in-app stack frames of every error in the chain;
+// messages, inputs and fields are left out as they vary between occurrences.
+func Fingerprint(err Error, opts ...FingerprintOption) string {
+    ignoreLines := false
+    for _, opt := range opts {
+        if opt == IgnoreLines {
+            ignoreLines = true
+        }
+    }
+
+    h := sha1.New()
+    for _, cause := range chain(err) {
+        _, _ = io.WriteString(h, cause.GetCode())
+        _, _ = io.WriteString(h, "\x00")
+        _, _ = io.WriteString(h, cause.GetType())
+        _, _ = io.WriteString(h, "\x00")
+        for _, frame := range cause.GetStacktrace().InApp() {
+            _, _ = io.WriteString(h, frame.Module)
+            _, _ = io.WriteString(h, ".")
+            _, _ = io.WriteString(h, frame.Function)
+            if !ignoreLines {
+                _, _ = io.WriteString(h, ":")
+                _, _ = io.WriteString(h, strconv.Itoa(frame.Lineno))
+            }
+            _, _ = io.WriteString(h, "\n")
+        }
+        _, _ = io.WriteString(h, "\x00")
+    }
+
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+type dedupEntry struct {
+    start      time.Time
+    last       Error
+    suppressed int
+}
+
+// A Deduper reports the first occurrence of an error and suppresses the
+// occurrences with the same fingerprint that follow within a window. The
+// number of occurrences is passed to the report function: the next
+// occurrence after the window is reported once, with a count of itself and
+// those suppressed before it. Suppressed occurrences not followed by another
+// are reported with their count when their window is swept or on Flush.
+type Deduper struct {
+    window time.Duration
+    report func(err Error, count int)
+    opts   []FingerprintOption
+    clock  Clock
+
+    mu        sync.Mutex
+    seen      map[string]*dedupEntry
+    lastSweep time.Time
+}
+
+func NewDeduper(window time.Duration, report func(err Error, count int), opts ...FingerprintOption) *Deduper {
+    return &Deduper{
+        window: window,
+        report: report,
+        opts:   opts,
+        clock:  systemClock{},
+        seen:   make(map[string]*dedupEntry),
+    }
+}
+
+// WithClock replaces the clock used to time the window.
+func (d *Deduper) WithClock(clock Clock) *Deduper {
+    d.clock = clock
+
+    return d
+}
+
+// Observe reports err unless an error with the same fingerprint was reported
+// within the window. It can be registered with OnReport.
+func (d *Deduper) Observe(err Error) {
+    if err == nil {
+        return
+    }
+
+    fp := Fingerprint(err, d.opts...)
+
+    d.mu.Lock()
+    now := d.clock.Now()
+
+    count := 0
+    entry, ok := d.seen[fp]
+    switch {
+    case !ok:
+        d.seen[fp] = &dedupEntry{start: now}
+        count = 1
+    case now.Before(entry.start.Add(d.window)):
+        entry.last = err
+        entry.suppressed++
+    default:
+        count = entry.suppressed + 1
+        d.seen[fp] = &dedupEntry{start: now}
+    }
+    // The entry of err is settled first, so that the sweep cannot report its
+    // suppressed occurrences apart from err.
+    pending := d.sweep(now)
+    d.mu.Unlock()
+
+    for _, entry := range pending {
+        d.report(entry.last, entry.suppressed)
+    }
+    if count > 0 {
+        d.report(err, count)
+    }
+}
+
+// Flush reports the occurrences suppressed so far and forgets all errors.
+func (d *Deduper) Flush() {
+    d.mu.Lock()
+    pending := make([]*dedupEntry, 0, len(d.seen))
+    for _, entry := range d.seen {
+        if entry.suppressed > 0 {
+            pending = append(pending, entry)
+        }
+    }
+    d.seen = make(map[string]*dedupEntry)
+    d.mu.Unlock()
+
+    for _, entry := range pending {
+        d.report(entry.last, entry.suppressed)
+    }
+}
+
+// sweep drops the entries whose window has passed, at most once per window,
+// and returns those with suppressed occurrences still to be reported.
+func (d *Deduper) sweep(now time.Time) []*dedupEntry {
+    if now.Before(d.lastSweep.Add(d.window)) {
+        return nil
+    }
+    d.lastSweep = now
+
+    var pending []*dedupEntry
+    for fp, entry := range d.seen {
+        if now.Before(entry.start.Add(d.window)) {
+            continue
+        }
+        delete(d.seen, fp)
+        if entry.suppressed > 0 {
+            pending = append(pending, entry)
+        }
+    }
+
+    return pending
+}
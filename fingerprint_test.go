@@ -0,0 +1,89 @@
+package errors
+
+import (
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/require"
+)
+
+func fpErr(code string) Error {
+    return NotFound(code, "err1")
+}
+
+func TestFingerprint(t *testing.T) {
+    errs := make([]Error, 2)
+    for i := range errs {
+        errs[i] = fpErr("code1").WithInput(i)
+    }
+    require.Equal(t, Fingerprint(errs[0]), Fingerprint(errs[1]))
+    require.Len(t, Fingerprint(errs[0]), 40)
+
+    require.NotEqual(t, Fingerprint(fpErr("code1")), Fingerprint(fpErr("code2")))
+
+    err1 := fpErr("code1")
+    err2 := fpErr("code1")
+    require.NotEqual(t, Fingerprint(err1), Fingerprint(err2))
+    require.Equal(t, Fingerprint(err1, IgnoreLines), Fingerprint(err2, IgnoreLines))
+
+    require.NotEqual(t, Fingerprint(x().(Error)), Fingerprint(y().(Error)))
+
+    require.True(t, isInApp(&StacktraceFrame{Module: "github.com/onedaycat/errors"}))
+    require.True(t, isInApp(&StacktraceFrame{Module: "main"}))
+    require.False(t, isInApp(&StacktraceFrame{Module: "net/http"}))
+    require.False(t, isInApp(&StacktraceFrame{Module: "runtime"}))
+}
+
+func TestDeduper(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    var reported []int
+    d := NewDeduper(time.Minute, func(err Error, count int) {
+        reported = append(reported, count)
+    }, IgnoreLines).WithClock(clock)
+
+    for i := 0; i < 3; i++ {
+        d.Observe(fpErr("code1"))
+    }
+    require.Equal(t, []int{1}, reported)
+
+    clock.Add(time.Minute)
+    d.Observe(fpErr("code1"))
+    require.Equal(t, []int{1, 3}, reported)
+
+    d.Observe(fpErr("code1"))
+    d.Observe(fpErr("code2"))
+    d.Flush()
+    require.Equal(t, []int{1, 3, 1, 1}, reported)
+}
+
+func TestDeduperWindowExpiry(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    reported := make(map[string][]int)
+    d := NewDeduper(time.Minute, func(err Error, count int) {
+        reported[err.GetCode()] = append(reported[err.GetCode()], count)
+    }, IgnoreLines).WithClock(clock)
+
+    // code1 expires on a sweep: the occurrence triggering it is counted with
+    // the suppressed ones.
+    d.Observe(fpErr("code1"))
+    d.Observe(fpErr("code1"))
+    clock.Add(30 * time.Second)
+    d.Observe(fpErr("code2"))
+    d.Observe(fpErr("code2"))
+    d.Observe(fpErr("code3"))
+    d.Observe(fpErr("code3"))
+    clock.Add(30 * time.Second)
+    d.Observe(fpErr("code1"))
+    require.Equal(t, []int{1, 2}, reported["code1"])
+
+    // code2 expires between sweeps, counted the same way.
+    clock.Add(30 * time.Second)
+    d.Observe(fpErr("code2"))
+    require.Equal(t, []int{1, 2}, reported["code2"])
+
+    // code3 expires without a new occurrence and is reported on the sweep.
+    clock.Add(30 * time.Second)
+    d.Observe(fpErr("code2"))
+    require.Equal(t, []int{1, 1}, reported["code3"])
+    require.Equal(t, []int{1, 2}, reported["code2"])
+}
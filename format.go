@@ -0,0 +1,288 @@
+package errors
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+    "sync/atomic"
+)
+
+// A Formatter lays out errors for humans. The one set with SetFormatter is
+// used for the %+v verb of errors and stacktraces, and for ErrorWithCause.
+type Formatter interface {
+    // FormatError writes err with its fields, stacktrace and causes.
+    FormatError(w io.Writer, err Error)
+    // FormatCauses writes the messages of err and its causes.
+    FormatCauses(w io.Writer, err Error)
+    // FormatStack writes a stacktrace, oldest frame first.
+    FormatStack(w io.Writer, stack Stacktrace)
+}
+
+var (
+    // ClassicFormatter writes each error of the chain on its own line,
+    // followed by its fields and one line per stack frame.
+    ClassicFormatter Formatter = classicFormatter{}
+    // CompactFormatter writes the error chain on a single line.
+    CompactFormatter Formatter = compactFormatter{}
+    // TreeFormatter indents every cause below the error it caused.
+    TreeFormatter Formatter = treeFormatter{}
+    // ColorFormatter is ClassicFormatter with ANSI colors for terminals.
+    // Frames of application code stand out from the standard library.
+    ColorFormatter Formatter = colorFormatter{}
+)
+
+type formatterHolder struct {
+    Formatter
+}
+
+var formatter atomic.Value
+
+func init() {
+    formatter.Store(formatterHolder{ClassicFormatter})
+}
+
+// SetFormatter sets the Formatter used by %+v and ErrorWithCause. The default
+// is ClassicFormatter.
+func SetFormatter(f Formatter) {
+    if f == nil {
+        f = ClassicFormatter
+    }
+    formatter.Store(formatterHolder{f})
+}
+
+func currentFormatter() Formatter {
+    return formatter.Load().(formatterHolder).Formatter
+}
+
+// chain returns err followed by its causes, stopping at the first cause that
+// is not an Error.
+func chain(err Error) []Error {
+    errs := make([]Error, 0, 4)
+    for err != nil {
+        errs = append(errs, err)
+        cause, ok := err.Unwrap().(Error)
+        if !ok {
+            break
+        }
+        err = cause
+    }
+
+    return errs
+}
+
+// causesString joins the operation, code and message of err and of each of its
+// causes with ": ", outermost first.
+func causesString(err Error) string {
+    var msgs []string
+    for _, cerr := range chain(err) {
+        if layer := layerString(cerr); layer != "" {
+            msgs = append(msgs, layer)
+        }
+    }
+
+    return strings.Join(msgs, ": ")
+}
+
+func sortedFields(fields Fields) []string {
+    keys := make([]string, 0, len(fields))
+    for key := range fields {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+
+    pairs := make([]string, len(keys))
+    for i, key := range keys {
+        pairs[i] = fmt.Sprintf("%s=%v", key, fields[key])
+    }
+
+    return pairs
+}
+
+func writeFields(w io.Writer, fields Fields) {
+    if len(fields) == 0 {
+        return
+    }
+
+    _, _ = fmt.Fprintf(w, "%s\n", strings.Join(sortedFields(fields), " "))
+}
+
+// asyncBoundary separates the stack of a goroutine from the stack that
+// launched it.
+const asyncBoundary = "--- async boundary ---"
+
+// writeViolations writes the violations carried by err itself, not by its
+// causes, one per line.
+func writeViolations(w io.Writer, indent string, err Error) {
+    ge, ok := err.(*GenericError)
+    if !ok {
+        return
+    }
+
+    for _, violation := range ge.violations {
+        _, _ = fmt.Fprintf(w, "%s- %s\n", indent, violation)
+    }
+}
+
+type classicFormatter struct{}
+
+func (f classicFormatter) FormatError(w io.Writer, err Error) {
+    for i, cerr := range chain(err) {
+        if i > 0 {
+            _, _ = io.WriteString(w, "\n")
+        }
+        _, _ = fmt.Fprintf(w, "%s\n", layerString(cerr))
+        writeFields(w, cerr.GetFields())
+        writeViolations(w, "", cerr)
+        f.FormatStack(w, cerr.GetStacktrace())
+        if async := cerr.GetAsyncStacktrace(); len(async) > 0 {
+            _, _ = io.WriteString(w, asyncBoundary+"\n")
+            f.FormatStack(w, async)
+        }
+        if frame := cerr.GetReceivedAt(); frame != nil {
+            _, _ = fmt.Fprintf(w, "received at %s\t%s:%d\n", frame.Function, frame.Filename, frame.Lineno)
+        }
+    }
+}
+
+func (classicFormatter) FormatCauses(w io.Writer, err Error) {
+    for _, cerr := range chain(err) {
+        _, _ = fmt.Fprintf(w, "%s\n", layerString(cerr))
+    }
+}
+
+func (classicFormatter) FormatStack(w io.Writer, stack Stacktrace) {
+    for _, frame := range stack {
+        _, _ = fmt.Fprintf(w, "%s\t%s:%d\n", frame.Function, frame.Filename, frame.Lineno)
+    }
+}
+
+type compactFormatter struct{}
+
+func (f compactFormatter) FormatError(w io.Writer, err Error) {
+    errs := chain(err)
+    f.FormatCauses(w, err)
+
+    var fields []string
+    for _, cerr := range errs {
+        fields = append(fields, sortedFields(cerr.GetFields())...)
+    }
+    if len(fields) > 0 {
+        _, _ = fmt.Fprintf(w, " {%s}", strings.Join(fields, " "))
+    }
+
+    if stack := errs[len(errs)-1].GetStacktrace(); len(stack) > 0 {
+        frame := stack[len(stack)-1]
+        _, _ = fmt.Fprintf(w, " at %s %s:%d", frame.Function, frame.Filename, frame.Lineno)
+    }
+}
+
+func (compactFormatter) FormatCauses(w io.Writer, err Error) {
+    _, _ = io.WriteString(w, causesString(err))
+}
+
+func (compactFormatter) FormatStack(w io.Writer, stack Stacktrace) {
+    _, _ = io.WriteString(w, strings.Join(stack.Strings(), " > "))
+}
+
+type treeFormatter struct{}
+
+func treeIndent(depth int) (head, body string) {
+    if depth == 0 {
+        return "", "    "
+    }
+
+    indent := strings.Repeat("    ", depth-1)
+
+    return indent + "└── ", indent + "        "
+}
+
+func (f treeFormatter) FormatError(w io.Writer, err Error) {
+    for depth, cerr := range chain(err) {
+        head, body := treeIndent(depth)
+        _, _ = fmt.Fprintf(w, "%s%s\n", head, layerString(cerr))
+        for _, field := range sortedFields(cerr.GetFields()) {
+            _, _ = fmt.Fprintf(w, "%s%s\n", body, field)
+        }
+        writeViolations(w, body, cerr)
+        for _, frame := range cerr.GetStacktrace() {
+            _, _ = fmt.Fprintf(w, "%sat %s %s:%d\n", body, frame.Function, frame.Filename, frame.Lineno)
+        }
+        if async := cerr.GetAsyncStacktrace(); len(async) > 0 {
+            _, _ = fmt.Fprintf(w, "%s%s\n", body, asyncBoundary)
+            for _, frame := range async {
+                _, _ = fmt.Fprintf(w, "%sat %s %s:%d\n", body, frame.Function, frame.Filename, frame.Lineno)
+            }
+        }
+        if frame := cerr.GetReceivedAt(); frame != nil {
+            _, _ = fmt.Fprintf(w, "%sreceived at %s %s:%d\n", body, frame.Function, frame.Filename, frame.Lineno)
+        }
+    }
+}
+
+func (treeFormatter) FormatCauses(w io.Writer, err Error) {
+    for depth, cerr := range chain(err) {
+        head, _ := treeIndent(depth)
+        _, _ = fmt.Fprintf(w, "%s%s\n", head, layerString(cerr))
+    }
+}
+
+func (treeFormatter) FormatStack(w io.Writer, stack Stacktrace) {
+    for _, frame := range stack {
+        _, _ = fmt.Fprintf(w, "at %s %s:%d\n", frame.Function, frame.Filename, frame.Lineno)
+    }
+}
+
+const (
+    colorReset  = "\x1b[0m"
+    colorBold   = "\x1b[1m"
+    colorDim    = "\x1b[2m"
+    colorRed    = "\x1b[31m"
+    colorYellow = "\x1b[33m"
+    colorCyan   = "\x1b[36m"
+)
+
+type colorFormatter struct{}
+
+func (f colorFormatter) FormatError(w io.Writer, err Error) {
+    for i, cerr := range chain(err) {
+        color := colorBold + colorRed
+        if i > 0 {
+            _, _ = io.WriteString(w, "\n")
+            color = colorYellow
+        }
+        _, _ = fmt.Fprintf(w, "%s%s%s\n", color, layerString(cerr), colorReset)
+        if fields := cerr.GetFields(); len(fields) > 0 {
+            _, _ = fmt.Fprintf(w, "%s%s%s\n", colorCyan, strings.Join(sortedFields(fields), " "), colorReset)
+        }
+        writeViolations(w, "", cerr)
+        f.FormatStack(w, cerr.GetStacktrace())
+        if async := cerr.GetAsyncStacktrace(); len(async) > 0 {
+            _, _ = fmt.Fprintf(w, "%s%s%s\n", colorCyan, asyncBoundary, colorReset)
+            f.FormatStack(w, async)
+        }
+        if frame := cerr.GetReceivedAt(); frame != nil {
+            _, _ = fmt.Fprintf(w, "%sreceived at%s %s\t%s:%d\n", colorCyan, colorReset, frame.Function, frame.Filename, frame.Lineno)
+        }
+    }
+}
+
+func (colorFormatter) FormatCauses(w io.Writer, err Error) {
+    for i, cerr := range chain(err) {
+        color := colorBold + colorRed
+        if i > 0 {
+            color = colorYellow
+        }
+        _, _ = fmt.Fprintf(w, "%s%s%s\n", color, layerString(cerr), colorReset)
+    }
+}
+
+func (colorFormatter) FormatStack(w io.Writer, stack Stacktrace) {
+    for _, frame := range stack {
+        color := colorDim
+        if isInApp(frame) {
+            color = colorBold
+        }
+        _, _ = fmt.Fprintf(w, "%s%s\t%s:%d%s\n", color, frame.Function, frame.Filename, frame.Lineno, colorReset)
+    }
+}
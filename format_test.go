@@ -0,0 +1,103 @@
+package errors
+
+import (
+    "flag"
+    "fmt"
+    "io/ioutil"
+    "path/filepath"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func formatErr() Error {
+    return &GenericError{
+        Code:    "code2",
+        Message: "err2",
+        fields:  Fields{"tenant": "t1"},
+        stacktrace: Stacktrace{
+            {Function: "main", Module: "main", Filename: "/app/main.go", Lineno: 10},
+            {Function: "Do", Module: "github.com/app/svc", Filename: "/app/svc/svc.go", Lineno: 20},
+        },
+        cause: &GenericError{
+            Code:    GenericCode,
+            Message: "err1",
+            stacktrace: Stacktrace{
+                {Function: "Read", Module: "os", Filename: "/go/src/os/file.go", Lineno: 30},
+            },
+        },
+    }
+}
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestFormatVerbs(t *testing.T) {
+    err := formatErr().WithInput(map[string]int{"id": 1}).WithPanic()
+
+    for _, tc := range []struct {
+        name   string
+        format string
+    }{
+        {"v", "%v"},
+        {"plus_v", "%+v"},
+        {"sharp_v", "%#v"},
+        {"sharp_plus_v", "%#+v"},
+        {"s", "%s"},
+        {"q", "%q"},
+        {"x", "%x"},
+        {"upper_x", "%X"},
+        {"d", "%d"},
+    } {
+        t.Run(tc.name, func(t *testing.T) {
+            result := fmt.Sprintf(tc.format, err)
+            golden := filepath.Join("testdata", "format", tc.name+".golden")
+            if *update {
+                require.NoError(t, ioutil.WriteFile(golden, []byte(result), 0644))
+            }
+
+            want, rerr := ioutil.ReadFile(golden)
+            require.NoError(t, rerr)
+            require.Equal(t, string(want), result)
+        })
+    }
+}
+
+func TestFormatter(t *testing.T) {
+    defer SetFormatter(nil)
+
+    err := formatErr()
+
+    require.Equal(t, "code2: err2\n"+
+        "tenant=t1\n"+
+        "main\t/app/main.go:10\n"+
+        "Do\t/app/svc/svc.go:20\n"+
+        "\n"+
+        "err1\n"+
+        "Read\t/go/src/os/file.go:30\n", fmt.Sprintf("%+v", err))
+    require.Equal(t, "code2: err2\nerr1\n", err.ErrorWithCause())
+
+    require.Equal(t, "code2: err2\n"+
+        "    tenant=t1\n"+
+        "    at main /app/main.go:10\n"+
+        "    at Do /app/svc/svc.go:20\n"+
+        "└── err1\n"+
+        "        at Read /go/src/os/file.go:30\n", fmt.Sprintf("%#+v", err))
+
+    SetFormatter(CompactFormatter)
+    require.Equal(t, "code2: err2: err1 {tenant=t1} at Read /go/src/os/file.go:30", fmt.Sprintf("%+v", err))
+    require.Equal(t, "code2: err2: err1", err.ErrorWithCause())
+    require.Equal(t, "main /app/main.go:10 > Do /app/svc/svc.go:20", fmt.Sprintf("%+v", err.GetStacktrace()))
+
+    SetFormatter(TreeFormatter)
+    require.Equal(t, "code2: err2\n└── err1\n", err.ErrorWithCause())
+    require.Equal(t, "at Read /go/src/os/file.go:30\n", fmt.Sprintf("%+v", err.RootError().GetStacktrace()))
+
+    SetFormatter(ColorFormatter)
+    result := fmt.Sprintf("%+v", err)
+    require.Contains(t, result, colorBold+colorRed+"code2: err2"+colorReset)
+    require.Contains(t, result, colorYellow+"err1"+colorReset)
+    require.Contains(t, result, colorBold+"Do\t/app/svc/svc.go:20"+colorReset)
+    require.Contains(t, result, colorDim+"Read\t/go/src/os/file.go:30"+colorReset)
+    require.Equal(t, "Read\t/go/src/os/file.go:30\n", err.RootError().GetStacktrace().String())
+    require.Equal(t, "Read\t/go/src/os/file.go:30\n", fmt.Sprint(err.RootError().GetStacktrace()))
+}
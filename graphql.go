@@ -0,0 +1,58 @@
+package errors
+
+// GraphQLError is an entry of the errors list of a GraphQL response.
+type GraphQLError struct {
+    Message    string                 `json:"message"`
+    Path       []interface{}          `json:"path,omitempty"`
+    Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *GraphQLError) Error() string {
+    return e.Message
+}
+
+// ToGraphQLError describes err as a GraphQL error raised at path. The code and
+// type are set as extensions, next to the fields of the error chain.
+func ToGraphQLError(err Error, path ...interface{}) *GraphQLError {
+    extensions := make(map[string]interface{})
+    for key, value := range err.GetAllFields() {
+        extensions[key] = value
+    }
+    extensions["code"] = err.GetCode()
+    if errType := err.GetType(); errType != NoneType {
+        extensions["type"] = errType
+    }
+
+    return &GraphQLError{
+        Message:    err.GetMessage(),
+        Path:       path,
+        Extensions: extensions,
+    }
+}
+
+// ParseGraphQLError rebuilds an Error from a GraphQL error. Extensions other
+// than code and type become fields. Like ParseJSONError, it marks the error as
+// remote and applies the definition registered for its code.
+func ParseGraphQLError(gqlErr *GraphQLError) Error {
+    e := &GenericError{
+        Code:    GenericCode,
+        Message: gqlErr.Message,
+    }
+
+    for key, value := range gqlErr.Extensions {
+        switch key {
+        case "code":
+            if code, ok := value.(string); ok && code != "" {
+                e.Code = code
+            }
+        case "type":
+            e.errType, _ = value.(string)
+        default:
+            e.WithField(key, value)
+        }
+    }
+    e.received("")
+    e.receivedAt = receivedAt(1)
+
+    return e
+}
@@ -0,0 +1,28 @@
+package errors
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestGraphQLError(t *testing.T) {
+    gqlErr := ToGraphQLError(NotFound("code1", "err1").WithField("id", "u1"), "user", 0)
+
+    b, err := json.Marshal(gqlErr)
+    require.NoError(t, err)
+    require.JSONEq(t, `{
+        "message": "err1",
+        "path": ["user", 0],
+        "extensions": {"code": "code1", "type": "NotFound", "id": "u1"}
+    }`, string(b))
+
+    decoded := &GraphQLError{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+
+    newErr := ParseGraphQLError(decoded)
+    require.EqualError(t, newErr, "code1: err1")
+    require.Equal(t, NotFoundType, newErr.GetType())
+    require.Equal(t, Fields{"id": "u1"}, newErr.GetFields())
+}
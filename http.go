@@ -11,6 +11,8 @@ func HttpStatus(errType string) int {
         return 403
     case NotFoundType:
         return 404
+    case ConflictType:
+        return 409
     case TimeoutType:
         return 441
     case InternalErrorType:
@@ -21,3 +23,28 @@ func HttpStatus(errType string) int {
 
     return 520
 }
+
+// HttpStatusType is the inverse of HttpStatus. Statuses without a dedicated
+// type map to NoneType.
+func HttpStatusType(status int) string {
+    switch status {
+    case 400:
+        return BadRequestType
+    case 401:
+        return UnauthorizedType
+    case 403:
+        return ForbiddenType
+    case 404:
+        return NotFoundType
+    case 409:
+        return ConflictType
+    case 441:
+        return TimeoutType
+    case 500:
+        return InternalErrorType
+    case 501:
+        return NotImplementType
+    }
+
+    return NoneType
+}
@@ -1,45 +1,192 @@
 package errors
 
+import (
+    "encoding/json"
+    "sync/atomic"
+)
+
+// JSONVerbosity selects what MarshalJSON writes for an error chain.
+type JSONVerbosity int32
+
+const (
+    // JSONFull writes everything JSON returns.
+    JSONFull JSONVerbosity = iota
+    // JSONNoStacktrace drops the stacktraces.
+    JSONNoStacktrace
+    // JSONCompact writes only code, message, type, operation, wrap and panic
+    // flags, severity and causes.
+    JSONCompact
+)
+
+var jsonVerbosity int32
+
+// SetJSONVerbosity sets the verbosity of MarshalJSON. The default is JSONFull.
+func SetJSONVerbosity(v JSONVerbosity) {
+    atomic.StoreInt32(&jsonVerbosity, int32(v))
+}
+
+// JSONError is the wire format of an error chain. V is the version of the
+// format and is set on the outermost error only. Members not known to this
+// version are kept in Extra, so they survive a decode and encode by an older
+// service.
 type JSONError struct {
+    V          int                `json:"v,omitempty"`
     Code       string             `json:"code,omitempty"`
     Message    string             `json:"message,omitempty"`
     ErrType    string             `json:"errType,omitempty"`
+    Op         string             `json:"op,omitempty"`
+    Wrapped    bool               `json:"wrapped,omitempty"`
     Cause      *JSONError         `json:"cause,omitempty"`
     Stacktrace []*StacktraceFrame `json:"stacktrace,omitempty"`
+    AsyncStack []*StacktraceFrame `json:"asyncStacktrace,omitempty"`
     Panic      bool               `json:"panic,omitempty"`
+    Severity   Severity           `json:"severity,omitempty"`
     Input      interface{}        `json:"input,omitempty"`
+    InputType  string             `json:"inputType,omitempty"`
+    Fields     Fields             `json:"fields,omitempty"`
+    Violations []*Violation       `json:"violations,omitempty"`
+    TraceID    string             `json:"traceId,omitempty"`
+    SpanID     string             `json:"spanId,omitempty"`
+    Origin     string             `json:"origin,omitempty"`
+
+    Extra map[string]json.RawMessage `json:"-"`
 }
 
 func (e *GenericError) JSON() *JSONError {
     jsonErr := &JSONError{
+        V:          WireVersion,
         Code:       e.Code,
         Message:    e.Message,
         ErrType:    e.errType,
+        Op:         e.op,
+        Wrapped:    e.wrapped,
         Panic:      e.panic,
+        Severity:   e.severity,
         Stacktrace: e.stacktrace,
+        AsyncStack: e.asyncStack,
         Input:      e.input,
+        Fields:     e.fields,
+        Violations: e.violations,
+        TraceID:    e.traceID,
+        SpanID:     e.spanID,
+        Extra:      e.extra,
+        Origin:     e.origin,
+    }
+
+    if !e.remote {
+        jsonErr.Origin = ServiceName()
+    }
+
+    if e.input != nil {
+        jsonErr.InputType = inputTypeName(e.input)
     }
 
     if e.cause != nil {
         jsonErr.Cause = e.cause.JSON()
+        jsonErr.Cause.V = 0
     }
 
     return jsonErr
 }
 
+// MarshalJSON writes the error chain in the JSONError shape, at the verbosity
+// set with SetJSONVerbosity.
+func (e *GenericError) MarshalJSON() ([]byte, error) {
+    jsonErr := e.JSON()
+    switch JSONVerbosity(atomic.LoadInt32(&jsonVerbosity)) {
+    case JSONNoStacktrace:
+        jsonErr = jsonErr.WithoutStacktrace()
+    case JSONCompact:
+        jsonErr = jsonErr.Compact()
+    }
+
+    return json.Marshal(jsonErr)
+}
+
+// UnmarshalJSON reads an error chain in the JSONError shape, as
+// ParseJSONError does.
+func (e *GenericError) UnmarshalJSON(b []byte) error {
+    jsonErr := &JSONError{}
+    if err := json.Unmarshal(b, jsonErr); err != nil {
+        return err
+    }
+
+    ge := parseJSONError(jsonErr)
+    ge.receivedAt = receivedAt(1)
+    *e = *ge
+
+    return nil
+}
+
+// ParseJSONError rebuilds an error chain from its JSON representation. The
+// errors are marked as remote, and the frame calling ParseJSONError is kept as
+// the place the error was received at. Errors without a type take it from the
+// definition registered for their code.
 func ParseJSONError(jsonErr *JSONError) Error {
+    ge := parseJSONError(jsonErr)
+    ge.receivedAt = receivedAt(1)
+
+    return ge
+}
+
+func parseJSONError(jsonErr *JSONError) *GenericError {
     ge := &GenericError{
         Code:       jsonErr.Code,
         Message:    jsonErr.Message,
         errType:    jsonErr.ErrType,
+        op:         jsonErr.Op,
+        wrapped:    jsonErr.Wrapped,
         panic:      jsonErr.Panic,
+        severity:   jsonErr.Severity,
         input:      jsonErr.Input,
+        fields:     jsonErr.Fields,
+        violations: jsonErr.Violations,
         stacktrace: jsonErr.Stacktrace,
+        asyncStack: jsonErr.AsyncStack,
+        traceID:    jsonErr.TraceID,
+        spanID:     jsonErr.SpanID,
+        extra:      jsonErr.Extra,
     }
+    ge.received(jsonErr.Origin)
 
     if jsonErr.Cause != nil {
-        ge.cause = ParseJSONError(jsonErr.Cause)
+        ge.cause = parseJSONError(jsonErr.Cause)
     }
 
     return ge
 }
+
+// WithoutStacktrace returns a copy of the error chain with the stacktraces
+// removed, for payloads sent to clients.
+func (e *JSONError) WithoutStacktrace() *JSONError {
+    if e == nil {
+        return nil
+    }
+
+    jsonErr := *e
+    jsonErr.Stacktrace = nil
+    jsonErr.AsyncStack = nil
+    jsonErr.Cause = e.Cause.WithoutStacktrace()
+
+    return &jsonErr
+}
+
+// Compact returns a copy of the error chain with only code, message, type,
+// operation, wrap and panic flags and severity.
+func (e *JSONError) Compact() *JSONError {
+    if e == nil {
+        return nil
+    }
+
+    return &JSONError{
+        V:        e.V,
+        Code:     e.Code,
+        Message:  e.Message,
+        ErrType:  e.ErrType,
+        Op:       e.Op,
+        Wrapped:  e.Wrapped,
+        Panic:    e.Panic,
+        Severity: e.Severity,
+        Cause:    e.Cause.Compact(),
+    }
+}
@@ -0,0 +1,106 @@
+package errors
+
+import "fmt"
+
+// Error codes defined by JSON-RPC 2.0, and the implementation defined server
+// error codes used for the other error types.
+const (
+    RPCParseError     = -32700
+    RPCInvalidRequest = -32600
+    RPCMethodNotFound = -32601
+    RPCInvalidParams  = -32602
+    RPCInternalError  = -32603
+    RPCServerError    = -32000
+    RPCUnauthorized   = -32001
+    RPCForbidden      = -32002
+    RPCNotFound       = -32003
+    RPCTimeout        = -32004
+    RPCConflict       = -32005
+)
+
+// RPCError is a JSON-RPC 2.0 error object. Data carries the full error chain
+// without stacktraces.
+type RPCError struct {
+    Code    int        `json:"code"`
+    Message string     `json:"message"`
+    Data    *JSONError `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+    return fmt.Sprintf("jsonrpc %d: %s", e.Code, e.Message)
+}
+
+func RPCCode(errType string) int {
+    switch errType {
+    case BadRequestType:
+        return RPCInvalidParams
+    case UnauthorizedType:
+        return RPCUnauthorized
+    case ForbiddenType:
+        return RPCForbidden
+    case NotFoundType:
+        return RPCNotFound
+    case TimeoutType:
+        return RPCTimeout
+    case ConflictType:
+        return RPCConflict
+    case InternalErrorType:
+        return RPCInternalError
+    case NotImplementType:
+        return RPCMethodNotFound
+    }
+
+    return RPCServerError
+}
+
+// RPCCodeType is the inverse of RPCCode. The protocol level codes without a
+// dedicated type, parse error and invalid request, map to BadRequestType.
+func RPCCodeType(code int) string {
+    switch code {
+    case RPCParseError, RPCInvalidRequest, RPCInvalidParams:
+        return BadRequestType
+    case RPCUnauthorized:
+        return UnauthorizedType
+    case RPCForbidden:
+        return ForbiddenType
+    case RPCNotFound:
+        return NotFoundType
+    case RPCTimeout:
+        return TimeoutType
+    case RPCConflict:
+        return ConflictType
+    case RPCInternalError:
+        return InternalErrorType
+    case RPCMethodNotFound:
+        return NotImplementType
+    }
+
+    return NoneType
+}
+
+func ToRPCError(err Error) *RPCError {
+    return &RPCError{
+        Code:    RPCCode(err.GetType()),
+        Message: err.Error(),
+        Data:    err.JSON().WithoutStacktrace(),
+    }
+}
+
+// ParseRPCError rebuilds an Error from a JSON-RPC error object. Without data,
+// only the message and the type derived from the code are known. Either way,
+// the error is marked as remote.
+func ParseRPCError(rpcErr *RPCError) Error {
+    if rpcErr.Data != nil {
+        return ParseJSONError(rpcErr.Data)
+    }
+
+    e := &GenericError{
+        Code:    GenericCode,
+        Message: rpcErr.Message,
+        errType: RPCCodeType(rpcErr.Code),
+    }
+    e.received("")
+    e.receivedAt = receivedAt(1)
+
+    return e
+}
@@ -0,0 +1,37 @@
+package errors
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestRPCError(t *testing.T) {
+    rpcErr := ToRPCError(x().(Error).WithInput(1))
+    require.Equal(t, RPCInternalError, rpcErr.Code)
+    require.Equal(t, "code2: err2", rpcErr.Message)
+    require.Nil(t, rpcErr.Data.Stacktrace)
+    require.Nil(t, rpcErr.Data.Cause.Stacktrace)
+    require.NotNil(t, x().(Error).GetStacktrace())
+
+    b, err := json.Marshal(rpcErr)
+    require.NoError(t, err)
+    decoded := &RPCError{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+
+    newErr := ParseRPCError(decoded)
+    require.Equal(t, x().Error(), newErr.Error())
+    require.Equal(t, InternalErrorType, newErr.GetType())
+    require.Equal(t, float64(1), newErr.GetInput())
+    require.EqualError(t, newErr.RootError(), "err0")
+
+    newErr = ParseRPCError(&RPCError{Code: RPCNotFound, Message: "err1"})
+    require.EqualError(t, newErr, "err1")
+    require.Equal(t, NotFoundType, newErr.GetType())
+
+    for _, errType := range []string{BadRequestType, UnauthorizedType, ForbiddenType, NotFoundType, TimeoutType, InternalErrorType, NotImplementType, ConflictType} {
+        require.Equal(t, errType, RPCCodeType(RPCCode(errType)))
+    }
+    require.Equal(t, RPCServerError, RPCCode(NoneType))
+}
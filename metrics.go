@@ -0,0 +1,208 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// An Observer is notified about errors, either when they are created or when
+// they are passed to Report.
+type Observer func(err Error)
+
+var (
+    observersMu     sync.RWMutex
+    createObservers []Observer
+    reportObservers []Observer
+)
+
+// OnCreate registers o to be called with every Error built by the
+// constructors of this package. o sees the error as the constructor built it,
+// before any With method is chained: the panic flag, severity, fields and
+// cause set afterwards are not visible, and o must not keep the error to read
+// them later.
+func OnCreate(o Observer) {
+    observersMu.Lock()
+    createObservers = append(createObservers, o)
+    observersMu.Unlock()
+}
+
+// OnReport registers o to be called with every Error passed to Report.
+func OnReport(o Observer) {
+    observersMu.Lock()
+    reportObservers = append(reportObservers, o)
+    observersMu.Unlock()
+}
+
+// ResetObservers removes all registered observers.
+func ResetObservers() {
+    observersMu.Lock()
+    createObservers = nil
+    reportObservers = nil
+    observersMu.Unlock()
+}
+
+// Report hands err to the observers registered with OnReport. Errors that do
+// not implement Error are wrapped first. A nil err is ignored.
+func Report(err error) {
+    if err == nil {
+        return
+    }
+
+    xerr := asError(err)
+
+    observersMu.RLock()
+    observers := reportObservers
+    observersMu.RUnlock()
+
+    for _, o := range observers {
+        o(xerr)
+    }
+}
+
+func created(e *GenericError) Error {
+    observersMu.RLock()
+    observers := createObservers
+    observersMu.RUnlock()
+
+    for _, o := range observers {
+        o(e)
+    }
+
+    return e
+}
+
+type metricKey struct {
+    code     string
+    errType  string
+    panic    bool
+    severity Severity
+}
+
+// Metric is the number of observed errors sharing a code, type, panic flag and
+// severity.
+type Metric struct {
+    Code     string   `json:"code"`
+    Type     string   `json:"type"`
+    Panic    bool     `json:"panic"`
+    Severity Severity `json:"severity"`
+    Count    uint64   `json:"count"`
+}
+
+// A Collector counts errors by code, type, panic flag and severity. Register
+// its Observe method with OnReport: the errors are then complete, whereas
+// OnCreate observers see them before their panic flag and severity are set.
+//
+// Collector is an http.Handler serving the counts in the Prometheus text
+// exposition format, and an expvar.Var so it can be published with
+// expvar.Publish.
+type Collector struct {
+    name string
+
+    mu     sync.Mutex
+    counts map[metricKey]uint64
+}
+
+// NewCollector returns a Collector exporting its counts under the metric
+// name, "errors_total" if name is empty.
+func NewCollector(name string) *Collector {
+    if name == "" {
+        name = "errors_total"
+    }
+
+    return &Collector{
+        name:   name,
+        counts: make(map[metricKey]uint64),
+    }
+}
+
+func (c *Collector) Observe(err Error) {
+    if err == nil {
+        return
+    }
+
+    key := metricKey{
+        code:     err.GetCode(),
+        errType:  err.GetType(),
+        panic:    err.IsPanic(),
+        severity: err.GetSeverity(),
+    }
+
+    c.mu.Lock()
+    c.counts[key]++
+    c.mu.Unlock()
+}
+
+// Metrics returns the current counts ordered by code, type, panic flag and
+// severity.
+func (c *Collector) Metrics() []Metric {
+    c.mu.Lock()
+    metrics := make([]Metric, 0, len(c.counts))
+    for key, count := range c.counts {
+        metrics = append(metrics, Metric{
+            Code:     key.code,
+            Type:     key.errType,
+            Panic:    key.panic,
+            Severity: key.severity,
+            Count:    count,
+        })
+    }
+    c.mu.Unlock()
+
+    sort.Slice(metrics, func(i, j int) bool {
+        if metrics[i].Code != metrics[j].Code {
+            return metrics[i].Code < metrics[j].Code
+        }
+        if metrics[i].Type != metrics[j].Type {
+            return metrics[i].Type < metrics[j].Type
+        }
+        if metrics[i].Panic != metrics[j].Panic {
+            return !metrics[i].Panic
+        }
+
+        return metrics[i].Severity < metrics[j].Severity
+    })
+
+    return metrics
+}
+
+// Reset clears all counts.
+func (c *Collector) Reset() {
+    c.mu.Lock()
+    c.counts = make(map[metricKey]uint64)
+    c.mu.Unlock()
+}
+
+// String returns the counts as a JSON array, implementing expvar.Var.
+func (c *Collector) String() string {
+    b, _ := json.Marshal(c.Metrics())
+
+    return string(b)
+}
+
+func (c *Collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    _, _ = w.Write([]byte(c.Prometheus()))
+}
+
+// Prometheus returns the counts in the Prometheus text exposition format.
+func (c *Collector) Prometheus() string {
+    sb := &strings.Builder{}
+    sb.WriteString(fmt.Sprintf("# HELP %s Number of errors by code, type, panic flag and severity.\n", c.name))
+    sb.WriteString(fmt.Sprintf("# TYPE %s counter\n", c.name))
+    for _, m := range c.Metrics() {
+        sb.WriteString(fmt.Sprintf("%s{code=\"%s\",type=\"%s\",panic=\"%t\",severity=\"%s\"} %d\n",
+            c.name, escapeLabel(m.Code), escapeLabel(m.Type), m.Panic, m.Severity, m.Count))
+    }
+
+    return sb.String()
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+    return labelReplacer.Replace(v)
+}
@@ -0,0 +1,72 @@
+package errors
+
+import (
+    "errors"
+    "expvar"
+    "net/http/httptest"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestObservers(t *testing.T) {
+    defer ResetObservers()
+
+    var created, reported []string
+    OnCreate(func(err Error) { created = append(created, err.GetCode()) })
+    OnReport(func(err Error) { reported = append(reported, err.GetCode()) })
+
+    _ = NotFound("code1", "err1")
+    _ = DefInternalError("code2", "err2").New()
+    Report(InternalError("code3", "err3"))
+    Report(errors.New("err4"))
+    Report(nil)
+
+    require.Equal(t, []string{"code1", "code2", "code3"}, created)
+    require.Equal(t, []string{"code3", GenericCode}, reported)
+}
+
+func TestCollector(t *testing.T) {
+    defer ResetObservers()
+
+    c := NewCollector("app_errors_total")
+    OnReport(c.Observe)
+    Report(NotFound("code1", "err1"))
+    Report(NotFound("code1", "err1"))
+    Report(InternalError("code2", "err2").WithPanic())
+    Report(InternalError("code2", "err2").WithSeverity(SeverityFatal))
+    Report(errors.New("err3"))
+    Report(nil)
+
+    require.Equal(t, []Metric{
+        {Code: GenericCode, Severity: SeverityError, Count: 1},
+        {Code: "code1", Type: NotFoundType, Severity: SeverityWarning, Count: 2},
+        {Code: "code2", Type: InternalErrorType, Severity: SeverityFatal, Count: 1},
+        {Code: "code2", Type: InternalErrorType, Panic: true, Severity: SeverityCritical, Count: 1},
+    }, c.Metrics())
+
+    rec := httptest.NewRecorder()
+    c.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+    require.Equal(t, "# HELP app_errors_total Number of errors by code, type, panic flag and severity.\n"+
+        "# TYPE app_errors_total counter\n"+
+        "app_errors_total{code=\"Generic\",type=\"\",panic=\"false\",severity=\"error\"} 1\n"+
+        "app_errors_total{code=\"code1\",type=\"NotFound\",panic=\"false\",severity=\"warning\"} 2\n"+
+        "app_errors_total{code=\"code2\",type=\"InternalError\",panic=\"false\",severity=\"fatal\"} 1\n"+
+        "app_errors_total{code=\"code2\",type=\"InternalError\",panic=\"true\",severity=\"critical\"} 1\n", rec.Body.String())
+
+    var v expvar.Var = c
+    require.JSONEq(t, `[
+        {"code":"Generic","type":"","panic":false,"severity":"error","count":1},
+        {"code":"code1","type":"NotFound","panic":false,"severity":"warning","count":2},
+        {"code":"code2","type":"InternalError","panic":false,"severity":"fatal","count":1},
+        {"code":"code2","type":"InternalError","panic":true,"severity":"critical","count":1}
+    ]`, v.String())
+
+    c.Reset()
+    require.Empty(t, c.Metrics())
+    require.Equal(t, "errors_total", NewCollector("").name)
+}
+
+func TestEscapeLabel(t *testing.T) {
+    require.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
@@ -0,0 +1,83 @@
+package errors
+
+import "fmt"
+
+// WithOp sets the operation that failed, such as "repo.load". It is shown
+// before the code and message of the error.
+func (e *GenericError) WithOp(op string) Error {
+    e.op = op
+
+    return e
+}
+
+func (e *GenericError) GetOp() string {
+    return e.op
+}
+
+// errorText returns the code and message of err, without operations.
+func errorText(err Error) string {
+    if ge, ok := err.(*GenericError); ok {
+        return ge.text()
+    }
+
+    return err.Error()
+}
+
+// layerString returns the operation, code and message of err alone, for the
+// formatters writing one error of a chain at a time.
+func layerString(err Error) string {
+    op, text := err.GetOp(), errorText(err)
+    switch {
+    case op == "":
+        return text
+    case text == "":
+        return op
+    }
+
+    return op + ": " + text
+}
+
+// Annotate sets *errp, if not nil, to an error with the operation given by
+// format and args, caused by *errp. It is meant to be deferred with a named
+// result:
+//
+//	func (r *Repo) Load(id string) (_ *User, err error) {
+//	    defer errors.Annotate(&err, "repo.load %s", id)
+//	    ...
+//	}
+//
+// The error keeps the type of *errp and the frame returning it.
+func Annotate(errp *error, format string, args ...interface{}) {
+    if errp == nil || *errp == nil {
+        return
+    }
+
+    cause := wrap(*errp, 2)
+    *errp = created(&GenericError{
+        Code:       GenericCode,
+        errType:    cause.GetType(),
+        op:         fmt.Sprintf(format, args...),
+        wrapped:    true,
+        stacktrace: NewStacktrace(1),
+    }).WithCause(cause)
+}
+
+// AnnotateAs sets *errp, if not nil, to an error of def caused by *errp, as
+// WrapWithDef does.
+func AnnotateAs(errp *error, def *ErrorDefinition) {
+    if errp == nil || *errp == nil {
+        return
+    }
+
+    *errp = wrapDef(def, *errp, 2)
+}
+
+// AnnotateType sets *errp, if not nil, to an error with the given type and
+// code caused by *errp, as WrapType does.
+func AnnotateType(errp *error, errType, code string) {
+    if errp == nil || *errp == nil {
+        return
+    }
+
+    *errp = wrapType(*errp, errType, code, 2)
+}
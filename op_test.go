@@ -0,0 +1,105 @@
+package errors
+
+import (
+    "encoding/json"
+    stderrors "errors"
+    "fmt"
+    "os"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+var errUserNotFound = DefNotFound("op1", "user not found")
+
+func queryUser(id string) error {
+    return errUserNotFound.New().WithOp("db.query")
+}
+
+func loadUser(id string) (err error) {
+    defer Annotate(&err, "repo.load")
+
+    return queryUser(id)
+}
+
+func handleUser(id string) (err error) {
+    defer Annotate(&err, "svc.handler %s", id)
+
+    if err = loadUser(id); err != nil {
+        return err
+    }
+
+    return nil
+}
+
+func TestAnnotate(t *testing.T) {
+    err := handleUser("42").(Error)
+    require.EqualError(t, err, "svc.handler 42: repo.load: db.query: op1: user not found")
+    require.Equal(t, "svc.handler 42: repo.load: db.query: op1: user not found", fmt.Sprint(err))
+    require.Equal(t, "svc.handler 42", err.GetOp())
+    require.Equal(t, GenericCode, err.GetCode())
+    require.Equal(t, NotFoundType, err.GetType())
+    require.True(t, errUserNotFound.Is(err))
+    require.Equal(t, "handleUser", err.GetStacktrace()[0].Function)
+    require.Len(t, err.GetStacktrace(), 1)
+    require.Equal(t, "svc.handler 42\nrepo.load\ndb.query: op1: user not found\n", err.ErrorWithCause())
+
+    var nilErr error
+    Annotate(&nilErr, "repo.load")
+    require.Nil(t, nilErr)
+
+    err = Wrap(err, "handling request")
+    require.EqualError(t, err, "handling request: svc.handler 42: repo.load: db.query: op1: user not found")
+    require.Equal(t, err.Error(), fmt.Sprint(err))
+
+    b, jerr := json.Marshal(err)
+    require.NoError(t, jerr)
+    decoded := &GenericError{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+    require.EqualError(t, decoded, err.Error())
+}
+
+func TestAnnotateWrapped(t *testing.T) {
+    var err error = InternalError("code1", "err1")
+    Annotate(&err, "c1")
+    err = Wrap(err, "handling")
+    Annotate(&err, "svc")
+    require.EqualError(t, err, "svc: handling: c1: code1: err1")
+    require.Equal(t, "svc\nhandling\nc1\ncode1: err1\n", err.(Error).ErrorWithCause())
+}
+
+func TestAnnotateAs(t *testing.T) {
+    def := DefForbidden("op2", "access denied")
+
+    open := func() (err error) {
+        defer AnnotateAs(&err, def)
+
+        _, err = os.Open("/does/not/exist")
+        return err
+    }
+    err := open().(Error)
+    require.EqualError(t, err, "op2: access denied")
+    require.Equal(t, ForbiddenType, err.GetType())
+    require.True(t, stderrors.Is(err, os.ErrNotExist))
+
+    typed := func() (err error) {
+        defer AnnotateType(&err, TimeoutType, "op3")
+
+        return stderrors.New("too slow")
+    }
+    err = typed().(Error)
+    require.EqualError(t, err, "op3: too slow")
+    require.Equal(t, TimeoutType, err.GetType())
+}
+
+func TestWithOp(t *testing.T) {
+    err := InternalError("code1", "err1").WithOp("db.query")
+    require.EqualError(t, err, "db.query: code1: err1")
+    require.Equal(t, `&errors.GenericError{Code:"code1", Message:"err1", Type:"InternalError", Op:"db.query"}`, fmt.Sprintf("%#v", err))
+
+    b, merr := err.(*GenericError).MarshalBinary()
+    require.NoError(t, merr)
+    decoded := &GenericError{}
+    require.NoError(t, decoded.UnmarshalBinary(b))
+    require.Equal(t, "db.query", decoded.GetOp())
+}
@@ -0,0 +1,100 @@
+// Package ozzoerr translates the errors of
+// github.com/go-ozzo/ozzo-validation into errors.Error.
+package ozzoerr
+
+import (
+    "sort"
+    "strconv"
+
+    validation "github.com/go-ozzo/ozzo-validation/v4"
+    "github.com/onedaycat/errors"
+)
+
+// A Translator turns validation.Errors into a BadRequestType error with one
+// violation per failed field.
+type Translator struct {
+    // Code and Message of the error, as for errors.ValidationError.ErrWithCode.
+    Code    string
+    Message string
+    // Rule names the rule a field broke. Defaults to the code of the
+    // validation error, e.g. "validation_required".
+    Rule func(err validation.Error) string
+}
+
+var defaultTranslator = &Translator{}
+
+// Translate translates err with the default Translator.
+func Translate(err error) errors.Error {
+    return defaultTranslator.Translate(err)
+}
+
+// Translate returns a BadRequestType error listing the fields that failed
+// validation, caused by err. A validation.InternalError becomes an
+// InternalErrorType error.
+func (t *Translator) Translate(err error) errors.Error {
+    if err == nil {
+        return nil
+    }
+    if ierr, ok := err.(validation.InternalError); ok {
+        return errors.InternalError("InvalidValidation", ierr.Error()).WithCause(err)
+    }
+
+    v := errors.NewValidation()
+    t.add(v, "", err)
+    if !v.HasViolations() {
+        return nil
+    }
+
+    return v.ErrWithCode(t.Code, t.Message).WithCause(err)
+}
+
+// add records the violations of err at path. Errors are visited in key order
+// so that the violations come out in a stable order.
+func (t *Translator) add(v *errors.ValidationError, path string, err error) {
+    switch x := err.(type) {
+    case nil:
+    case validation.Errors:
+        keys := make([]string, 0, len(x))
+        for key := range x {
+            keys = append(keys, key)
+        }
+        sort.Slice(keys, func(i, j int) bool {
+            return keyLess(keys[i], keys[j])
+        })
+        for _, key := range keys {
+            t.add(v, errors.JoinPath(path, pathElem(key)), x[key])
+        }
+    case validation.Error:
+        v.Add(path, t.rule(x), x.Message(), nil)
+    default:
+        v.Add(path, "", x.Error(), nil)
+    }
+}
+
+func (t *Translator) rule(err validation.Error) string {
+    if t.Rule != nil {
+        return t.Rule(err)
+    }
+
+    return err.Code()
+}
+
+// keyLess orders slice indexes numerically and other keys alphabetically.
+func keyLess(a, b string) bool {
+    ai, aerr := strconv.Atoi(a)
+    bi, berr := strconv.Atoi(b)
+    if aerr == nil && berr == nil {
+        return ai < bi
+    }
+
+    return a < b
+}
+
+// pathElem renders the indexes of slices as "[i]".
+func pathElem(key string) string {
+    if i, err := strconv.Atoi(key); err == nil {
+        return errors.Path(i)
+    }
+
+    return key
+}
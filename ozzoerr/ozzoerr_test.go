@@ -0,0 +1,68 @@
+package ozzoerr
+
+import (
+    "fmt"
+    "testing"
+
+    validation "github.com/go-ozzo/ozzo-validation/v4"
+    "github.com/onedaycat/errors"
+    "github.com/stretchr/testify/require"
+)
+
+type item struct {
+    Price int
+}
+
+func (i item) Validate() error {
+    return validation.ValidateStruct(&i,
+        validation.Field(&i.Price, validation.Required),
+    )
+}
+
+type order struct {
+    Name  string
+    Items []item
+}
+
+func (o order) Validate() error {
+    return validation.ValidateStruct(&o,
+        validation.Field(&o.Name, validation.Required.Error("is required")),
+        validation.Field(&o.Items),
+    )
+}
+
+func TestTranslate(t *testing.T) {
+    require.Nil(t, Translate(nil))
+
+    items := make([]item, 12)
+    for i := range items {
+        items[i].Price = 1
+    }
+    items[2].Price = 0
+    items[10].Price = 0
+
+    verr := order{Items: items}.Validate()
+    err := Translate(verr)
+    require.EqualError(t, err, "ValidationFailed: validation failed")
+    require.Equal(t, errors.BadRequestType, err.GetType())
+    require.Equal(t, []*errors.Violation{
+        {Field: "Items[2].Price", Rule: "validation_required", Message: "cannot be blank"},
+        {Field: "Items[10].Price", Rule: "validation_required", Message: "cannot be blank"},
+        {Field: "Name", Rule: "validation_required", Message: "is required"},
+    }, err.GetViolations())
+    require.Equal(t, verr.Error(), err.Unwrap().Error())
+
+    tr := &Translator{
+        Code:    "InvalidOrder",
+        Message: "invalid order",
+        Rule:    func(err validation.Error) string { return err.Params()["rule"].(string) },
+    }
+    err = tr.Translate(validation.Errors{
+        "name": validation.NewError("custom", "is taken").SetParams(map[string]interface{}{"rule": "unique"}),
+    })
+    require.EqualError(t, err, "InvalidOrder: invalid order")
+    require.Equal(t, []*errors.Violation{{Field: "name", Rule: "unique", Message: "is taken"}}, err.GetViolations())
+
+    err = Translate(validation.NewInternalError(fmt.Errorf("bad rule")))
+    require.Equal(t, errors.InternalErrorType, err.GetType())
+}
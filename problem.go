@@ -0,0 +1,275 @@
+package errors
+
+import (
+    "crypto/rand"
+    "encoding/json"
+    "fmt"
+    "net/url"
+    "strings"
+    "sync"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 9457 problem details document. Extensions holds the
+// extension members, which are serialized next to the standard members.
+type Problem struct {
+    Type       string
+    Title      string
+    Status     int
+    Detail     string
+    Instance   string
+    Extensions map[string]interface{}
+}
+
+var problemMembers = map[string]bool{
+    "type":     true,
+    "title":    true,
+    "status":   true,
+    "detail":   true,
+    "instance": true,
+}
+
+// problemExtensions are the extension members set by ToProblem itself.
+var problemExtensions = map[string]bool{
+    "code":       true,
+    "inputs":     true,
+    "violations": true,
+    "traceId":    true,
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+    m := make(map[string]interface{}, len(p.Extensions)+5)
+    for key, value := range p.Extensions {
+        if !problemMembers[key] {
+            m[key] = value
+        }
+    }
+    if p.Type != "" {
+        m["type"] = p.Type
+    }
+    if p.Title != "" {
+        m["title"] = p.Title
+    }
+    if p.Status != 0 {
+        m["status"] = p.Status
+    }
+    if p.Detail != "" {
+        m["detail"] = p.Detail
+    }
+    if p.Instance != "" {
+        m["instance"] = p.Instance
+    }
+
+    return json.Marshal(m)
+}
+
+func (p *Problem) UnmarshalJSON(b []byte) error {
+    var m map[string]json.RawMessage
+    if err := json.Unmarshal(b, &m); err != nil {
+        return err
+    }
+
+    *p = Problem{}
+    for key, raw := range m {
+        var err error
+        switch key {
+        case "type":
+            err = json.Unmarshal(raw, &p.Type)
+        case "title":
+            err = json.Unmarshal(raw, &p.Title)
+        case "status":
+            err = json.Unmarshal(raw, &p.Status)
+        case "detail":
+            err = json.Unmarshal(raw, &p.Detail)
+        case "instance":
+            err = json.Unmarshal(raw, &p.Instance)
+        default:
+            var value interface{}
+            err = json.Unmarshal(raw, &value)
+            if p.Extensions == nil {
+                p.Extensions = make(map[string]interface{})
+            }
+            p.Extensions[key] = value
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+var (
+    problemMu       sync.RWMutex
+    problemTypeBase string
+    problemInstance = randomInstance
+)
+
+// SetProblemTypeBase sets the URI the error code is appended to when building
+// the problem type, e.g. "https://example.com/problems/".
+func SetProblemTypeBase(base string) {
+    problemMu.Lock()
+    problemTypeBase = base
+    problemMu.Unlock()
+}
+
+// SetProblemInstance sets the function giving the instance URI of a problem.
+// By default every problem gets a random "urn:uuid:" URI.
+func SetProblemInstance(fn func(err Error) string) {
+    problemMu.Lock()
+    problemInstance = fn
+    problemMu.Unlock()
+}
+
+func randomInstance(_ Error) string {
+    var b [16]byte
+    if _, err := rand.Read(b[:]); err != nil {
+        return ""
+    }
+    b[6] = b[6]&0x0f | 0x40
+    b[8] = b[8]&0x3f | 0x80
+
+    return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// ToProblem describes err as a problem document. The code becomes the problem
+// type, the message the title, and the messages of the whole cause chain the
+// detail. Code, trace ID, inputs, violations and fields are added as extension
+// members; fields named like a member are left out. A nil err gives nil.
+func ToProblem(err Error) *Problem {
+    if err == nil {
+        return nil
+    }
+
+    problemMu.RLock()
+    base := problemTypeBase
+    instance := problemInstance
+    problemMu.RUnlock()
+
+    p := &Problem{
+        Type:       "about:blank",
+        Title:      err.GetMessage(),
+        Status:     HttpStatus(err.GetType()),
+        Extensions: make(map[string]interface{}),
+    }
+
+    if code := err.GetCode(); code != "" && code != GenericCode {
+        p.Type = base + url.PathEscape(code)
+        p.Extensions["code"] = code
+    }
+
+    messages := []string{err.GetMessage()}
+    for _, cerr := range chain(err)[1:] {
+        messages = append(messages, layerString(cerr))
+    }
+    p.Detail = strings.Join(messages, ": ")
+
+    if instance != nil {
+        p.Instance = instance(err)
+    }
+
+    for key, value := range err.GetAllFields() {
+        if !problemMembers[key] && !problemExtensions[key] {
+            p.Extensions[key] = value
+        }
+    }
+    if inputs := err.GetAllInputs(); len(inputs) > 0 {
+        p.Extensions["inputs"] = inputs
+    }
+    if violations := err.GetViolations(); len(violations) > 0 {
+        p.Extensions["violations"] = violations
+    }
+    if traceID := err.GetTraceID(); traceID != "" {
+        p.Extensions["traceId"] = traceID
+    }
+
+    return p
+}
+
+// FromProblem rebuilds an Error from a problem document produced by
+// ToProblem. The type is derived from the status, and the extension members
+// other than code, inputs, violations and traceId become fields. Like
+// ParseJSONError, it marks the error as remote and applies the definition
+// registered for its code.
+func FromProblem(p *Problem) Error {
+    problemMu.RLock()
+    base := problemTypeBase
+    problemMu.RUnlock()
+
+    e := &GenericError{
+        Code:    GenericCode,
+        Message: p.Title,
+        errType: HttpStatusType(p.Status),
+    }
+
+    if code, ok := p.Extensions["code"].(string); ok {
+        e.Code = code
+    } else if code := problemTypeCode(p.Type, base); code != "" {
+        e.Code = code
+    }
+
+    for key, value := range p.Extensions {
+        switch key {
+        case "code":
+        case "inputs":
+            if inputs, ok := value.([]interface{}); ok && len(inputs) == 1 {
+                e.input = inputs[0]
+            } else {
+                e.input = value
+            }
+        case "traceId":
+            e.traceID, _ = value.(string)
+        case "violations":
+            e.violations = parseViolations(value)
+        default:
+            e.WithField(key, value)
+        }
+    }
+    e.received("")
+    e.receivedAt = receivedAt(1)
+
+    return e
+}
+
+// problemTypeCode returns the code at the end of a problem type: what follows
+// base, or the last path segment when the type is not under base.
+func problemTypeCode(problemType, base string) string {
+    if problemType == "" || problemType == "about:blank" {
+        return ""
+    }
+
+    escaped := ""
+    if base != "" && strings.HasPrefix(problemType, base) {
+        escaped = strings.TrimPrefix(problemType, base)
+    } else if u, err := url.Parse(problemType); err == nil {
+        escaped = u.EscapedPath()
+        if i := strings.LastIndex(escaped, "/"); i >= 0 {
+            escaped = escaped[i+1:]
+        }
+    }
+
+    code, err := url.PathUnescape(escaped)
+    if err != nil {
+        return ""
+    }
+
+    return code
+}
+
+func parseViolations(value interface{}) []*Violation {
+    if violations, ok := value.([]*Violation); ok {
+        return violations
+    }
+
+    b, err := json.Marshal(value)
+    if err != nil {
+        return nil
+    }
+    var violations []*Violation
+    if err := json.Unmarshal(b, &violations); err != nil {
+        return nil
+    }
+
+    return violations
+}
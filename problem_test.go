@@ -0,0 +1,97 @@
+package errors
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestProblem(t *testing.T) {
+    defer SetProblemTypeBase("")
+    SetProblemTypeBase("https://example.com/problems/")
+
+    err := NotFound("user not found", "err1").
+        WithInput("u1").
+        WithField("tenant", "t1").
+        WithCause(InternalError("code0", "err0"))
+
+    p := ToProblem(err)
+    require.Equal(t, "https://example.com/problems/user%20not%20found", p.Type)
+    require.Equal(t, "err1", p.Title)
+    require.Equal(t, 404, p.Status)
+    require.Equal(t, "err1: code0: err0", p.Detail)
+    require.Regexp(t, "^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$", p.Instance)
+
+    b, jerr := json.Marshal(p)
+    require.NoError(t, jerr)
+    require.JSONEq(t, `{
+        "type": "https://example.com/problems/user%20not%20found",
+        "title": "err1",
+        "status": 404,
+        "detail": "err1: code0: err0",
+        "instance": "`+p.Instance+`",
+        "code": "user not found",
+        "tenant": "t1",
+        "inputs": ["u1"]
+    }`, string(b))
+
+    decoded := &Problem{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+
+    newErr := FromProblem(decoded)
+    require.Equal(t, "user not found", newErr.GetCode())
+    require.Equal(t, NotFoundType, newErr.GetType())
+    require.Equal(t, "err1", newErr.GetMessage())
+    require.Equal(t, "u1", newErr.GetInput())
+    require.Equal(t, Fields{"tenant": "t1"}, newErr.GetFields())
+
+    delete(decoded.Extensions, "code")
+    require.Equal(t, "user not found", FromProblem(decoded).GetCode())
+}
+
+func TestProblemGeneric(t *testing.T) {
+    defer SetProblemInstance(randomInstance)
+    SetProblemInstance(func(err Error) string { return "/errors/1" })
+
+    p := ToProblem(New("err1"))
+    require.Equal(t, "about:blank", p.Type)
+    require.Equal(t, 520, p.Status)
+    require.Equal(t, "/errors/1", p.Instance)
+    require.Empty(t, p.Extensions)
+
+    newErr := FromProblem(p)
+    require.Equal(t, GenericCode, newErr.GetCode())
+    require.Equal(t, NoneType, newErr.GetType())
+
+    require.Nil(t, ToProblem(nil))
+}
+
+func TestProblemReservedFields(t *testing.T) {
+    err := NotFound("code1", "err1").
+        WithFields(Fields{"code": "code2", "traceId": "t2", "title": "err2", "tenant": "t1"})
+
+    p := ToProblem(err)
+    require.Equal(t, "err1", p.Title)
+    require.Equal(t, map[string]interface{}{"code": "code1", "tenant": "t1"}, p.Extensions)
+    require.Equal(t, "code1", FromProblem(p).GetCode())
+}
+
+func TestProblemTypeCode(t *testing.T) {
+    for _, tc := range []struct {
+        problemType, base, code string
+    }{
+        {"https://example.com/problems/user%20not%20found", "https://example.com/problems/", "user not found"},
+        {"https://example.com/problems/user%20not%20found", "", "user not found"},
+        {"https://other.com/errors/code1?lang=en", "https://example.com/problems/", "code1"},
+        {"code1", "", "code1"},
+        {"about:blank", "", ""},
+        {"", "https://example.com/problems/", ""},
+    } {
+        require.Equal(t, tc.code, problemTypeCode(tc.problemType, tc.base), tc.problemType)
+    }
+
+    err := FromProblem(&Problem{Type: "https://example.com/problems/code1", Title: "err1", Status: 404})
+    require.Equal(t, "code1", err.GetCode())
+    require.Equal(t, NotFoundType, err.GetType())
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+    "reflect"
+    "strings"
+    "sync/atomic"
+)
+
+var serviceName atomic.Value
+
+// SetServiceName sets the name of this service. It is written as the origin
+// of the errors created here when they are encoded.
+func SetServiceName(name string) {
+    serviceName.Store(name)
+}
+
+func ServiceName() string {
+    name, _ := serviceName.Load().(string)
+
+    return name
+}
+
+// IsRemote reports whether the error was decoded from another process rather
+// than created in this one.
+func (e *GenericError) IsRemote() bool {
+    return e.remote
+}
+
+// GetOrigin returns the name of the service a remote error was created in, if
+// the sender set one with SetServiceName.
+func (e *GenericError) GetOrigin() string {
+    return e.origin
+}
+
+// GetReceivedAt returns the local frame that decoded a remote error. The
+// stacktrace of a remote error is the one captured by the sender.
+func (e *GenericError) GetReceivedAt() *StacktraceFrame {
+    return e.receivedAt
+}
+
+// received marks e as decoded from another process, where it was created in
+// origin, and completes it from the definition registered for its code. All
+// decoders go through it.
+func (e *GenericError) received(origin string) {
+    e.remote = true
+    e.origin = origin
+    e.applyDefinition()
+}
+
+func (e *GenericError) applyDefinition() {
+    if e.errType != NoneType {
+        return
+    }
+
+    if def := LookupDef(e.Code); def != nil {
+        e.errType = def.Type
+        if e.Message == "" {
+            e.Message = def.Message
+        }
+    }
+}
+
+var pkgPath = reflect.TypeOf(GenericError{}).PkgPath()
+
+// receivedAt returns the innermost frame of the caller that is not part of
+// the decoding machinery: encoding packages, reflection, the methods of this
+// package and its Parse functions.
+func receivedAt(skip int) *StacktraceFrame {
+    stack := NewStacktrace(skip + 1)
+    for i := len(stack) - 1; i >= 0; i-- {
+        frame := stack[i]
+        switch {
+        case strings.HasPrefix(frame.Module, "encoding/"), frame.Module == "reflect":
+        case strings.HasPrefix(frame.Module, pkgPath+".("):
+        case frame.Module == pkgPath && strings.HasPrefix(frame.Function, "Parse"):
+        default:
+            return frame
+        }
+    }
+
+    return nil
+}
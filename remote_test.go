@@ -0,0 +1,86 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestRemote(t *testing.T) {
+    defer SetServiceName("")
+    SetServiceName("billing")
+
+    err := x().(Error)
+    require.False(t, err.IsRemote())
+    require.Nil(t, err.GetReceivedAt())
+
+    jsonErr := err.JSON()
+    require.Equal(t, "billing", jsonErr.Origin)
+    require.Equal(t, "billing", jsonErr.Cause.Origin)
+
+    SetServiceName("gateway")
+
+    newErr := ParseJSONError(jsonErr)
+    require.True(t, newErr.IsRemote())
+    require.Equal(t, "billing", newErr.GetOrigin())
+    require.Equal(t, err.GetStacktrace(), newErr.GetStacktrace())
+    require.Equal(t, "TestRemote", newErr.GetReceivedAt().Function)
+    require.True(t, newErr.Unwrap().(Error).IsRemote())
+    require.Equal(t, "billing", newErr.JSON().Origin)
+    require.Contains(t, fmt.Sprintf("%+v", newErr), "received at TestRemote\t")
+
+    b, _ := json.Marshal(err)
+    decoded := &GenericError{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+    require.Equal(t, "TestRemote", decoded.GetReceivedAt().Function)
+
+    b, _ = err.(*GenericError).MarshalBinary()
+    decoded = &GenericError{}
+    require.NoError(t, decoded.UnmarshalBinary(b))
+    require.True(t, decoded.IsRemote())
+    require.Equal(t, "gateway", decoded.GetOrigin())
+    require.Equal(t, "TestRemote", decoded.GetReceivedAt().Function)
+}
+
+func TestRemoteDefinition(t *testing.T) {
+    defer ResetDefinitions()
+
+    def := DefNotFound("remote.user_not_found", "user not found")
+    require.Nil(t, LookupDef("remote.user_not_found"))
+    require.Equal(t, NoneType, ParseJSONError(&JSONError{Code: "remote.user_not_found"}).GetType())
+
+    Register(def)
+    require.Equal(t, def, LookupDef("remote.user_not_found"))
+
+    newErr := ParseJSONError(&JSONError{Code: "remote.user_not_found"})
+    require.True(t, def.Is(newErr))
+    require.Equal(t, NotFoundType, newErr.GetType())
+    require.EqualError(t, newErr, "remote.user_not_found: user not found")
+
+    newErr = ParseJSONError(&JSONError{Code: "remote.user_not_found", ErrType: BadRequestType, Message: "err1"})
+    require.Equal(t, BadRequestType, newErr.GetType())
+    require.EqualError(t, newErr, "remote.user_not_found: err1")
+}
+
+func TestRemoteDecoders(t *testing.T) {
+    defer ResetDefinitions()
+    Register(DefNotFound("remote.user_not_found", "user not found"))
+
+    for name, newErr := range map[string]Error{
+        "problem": FromProblem(&Problem{Extensions: map[string]interface{}{"code": "remote.user_not_found"}}),
+        "graphql": ParseGraphQLError(&GraphQLError{Extensions: map[string]interface{}{"code": "remote.user_not_found"}}),
+        "jsonrpc": ParseRPCError(&RPCError{Code: -32000, Data: &JSONError{Code: "remote.user_not_found"}}),
+    } {
+        require.True(t, newErr.IsRemote(), name)
+        require.Equal(t, "remote.user_not_found", newErr.GetCode(), name)
+        require.Equal(t, NotFoundType, newErr.GetType(), name)
+        require.Equal(t, "user not found", newErr.GetMessage(), name)
+        require.Equal(t, "TestRemoteDecoders", newErr.GetReceivedAt().Function, name)
+    }
+
+    newErr := ParseRPCError(&RPCError{Code: -32603, Message: "err1"})
+    require.True(t, newErr.IsRemote())
+    require.Equal(t, "TestRemoteDecoders", newErr.GetReceivedAt().Function)
+}
@@ -0,0 +1,462 @@
+package errors
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "io/ioutil"
+    "net/http"
+    "os"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// A Reporter delivers errors out of the process.
+type Reporter interface {
+    Report(err Error)
+    Flush(ctx context.Context) error
+}
+
+// A Sink is where a Reporter delivers errors to, a batch at a time.
+type Sink interface {
+    Write(ctx context.Context, errs []Error) error
+}
+
+// DropPolicy decides what a Reporter does with an error when its queue is full.
+type DropPolicy int
+
+const (
+    // DropNewest discards the error being reported.
+    DropNewest DropPolicy = iota
+    // DropOldest discards the oldest queued error to make room.
+    DropOldest
+    // Block waits for room in the queue, or until the reporter is closed.
+    Block
+)
+
+type ReporterOptions struct {
+    // QueueSize bounds the number of errors waiting for delivery. Defaults
+    // to 1024.
+    QueueSize int
+    // BatchSize is the largest number of errors written to the sink at once.
+    // Defaults to 100.
+    BatchSize int
+    // FlushInterval is how long an incomplete batch waits before being
+    // written. Defaults to one second.
+    FlushInterval time.Duration
+    // DropPolicy applies when the queue is full.
+    DropPolicy DropPolicy
+    // Filter, if set, selects the errors to report.
+    Filter func(err Error) bool
+    // RateLimit, if positive, is the number of errors per second accepted
+    // on average, with bursts of up to Burst errors. Errors above the rate
+    // are dropped.
+    RateLimit float64
+    Burst     int
+    // OnError is called with the errors returned by the sink.
+    OnError func(err error)
+    // Clock times the rate limit.
+    Clock Clock
+}
+
+// FilterTypes selects the errors with one of the given types.
+func FilterTypes(types ...string) func(err Error) bool {
+    return func(err Error) bool {
+        for _, errType := range types {
+            if err.GetType() == errType {
+                return true
+            }
+        }
+
+        return false
+    }
+}
+
+// FilterPanic selects the errors raised by a panic.
+func FilterPanic(err Error) bool {
+    return err.IsPanic()
+}
+
+type flushRequest struct {
+    ctx   context.Context
+    reply chan error
+}
+
+// An AsyncReporter queues reported errors and writes them to its sink in
+// batches from a background goroutine.
+type AsyncReporter struct {
+    sink Sink
+    opts ReporterOptions
+
+    queue   chan Error
+    flushc  chan flushRequest
+    done    chan struct{}
+    stopped chan struct{}
+    dropped uint64
+
+    mu     sync.RWMutex
+    closed bool
+
+    limitMu  sync.Mutex
+    tokens   float64
+    lastFill time.Time
+}
+
+func NewAsyncReporter(sink Sink, opts ReporterOptions) *AsyncReporter {
+    if opts.QueueSize <= 0 {
+        opts.QueueSize = 1024
+    }
+    if opts.BatchSize <= 0 {
+        opts.BatchSize = 100
+    }
+    if opts.FlushInterval <= 0 {
+        opts.FlushInterval = time.Second
+    }
+    if opts.Burst <= 0 {
+        opts.Burst = int(opts.RateLimit)
+        if opts.Burst < 1 {
+            opts.Burst = 1
+        }
+    }
+    if opts.Clock == nil {
+        opts.Clock = systemClock{}
+    }
+
+    r := &AsyncReporter{
+        sink:     sink,
+        opts:     opts,
+        queue:    make(chan Error, opts.QueueSize),
+        flushc:   make(chan flushRequest),
+        done:     make(chan struct{}),
+        stopped:  make(chan struct{}),
+        tokens:   float64(opts.Burst),
+        lastFill: opts.Clock.Now(),
+    }
+    go r.run()
+
+    return r
+}
+
+// Report queues err for delivery unless it is filtered out, over the rate
+// limit or the queue is full. It can be registered with OnReport.
+func (r *AsyncReporter) Report(err Error) {
+    if err == nil {
+        return
+    }
+    if r.opts.Filter != nil && !r.opts.Filter(err) {
+        return
+    }
+    if !r.allow() {
+        atomic.AddUint64(&r.dropped, 1)
+        return
+    }
+    if r.opts.DropPolicy == Block {
+        r.block(err)
+        return
+    }
+
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    if r.closed {
+        atomic.AddUint64(&r.dropped, 1)
+        return
+    }
+
+    switch r.opts.DropPolicy {
+    case DropOldest:
+        for {
+            select {
+            case r.queue <- err:
+                return
+            default:
+            }
+            select {
+            case <-r.queue:
+                atomic.AddUint64(&r.dropped, 1)
+            default:
+            }
+        }
+    default:
+        select {
+        case r.queue <- err:
+        default:
+            atomic.AddUint64(&r.dropped, 1)
+        }
+    }
+}
+
+// block waits for room in the queue without holding r.mu, so that a stalled
+// sink does not hold up Close. The error is dropped once r is closed.
+func (r *AsyncReporter) block(err Error) {
+    select {
+    case <-r.done:
+        atomic.AddUint64(&r.dropped, 1)
+        return
+    default:
+    }
+
+    select {
+    case r.queue <- err:
+    case <-r.done:
+        atomic.AddUint64(&r.dropped, 1)
+    }
+}
+
+// Dropped returns the number of errors discarded so far.
+func (r *AsyncReporter) Dropped() uint64 {
+    return atomic.LoadUint64(&r.dropped)
+}
+
+// Flush writes the queued errors to the sink and waits for the writes to end.
+func (r *AsyncReporter) Flush(ctx context.Context) error {
+    req := flushRequest{ctx: ctx, reply: make(chan error, 1)}
+
+    select {
+    case r.flushc <- req:
+    case <-r.stopped:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+
+    select {
+    case err := <-req.reply:
+        return err
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+// Close stops accepting errors, writes those still queued and stops the
+// background goroutine.
+func (r *AsyncReporter) Close(ctx context.Context) error {
+    r.mu.Lock()
+    if !r.closed {
+        r.closed = true
+        close(r.done)
+    }
+    r.mu.Unlock()
+
+    select {
+    case <-r.stopped:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+func (r *AsyncReporter) allow() bool {
+    if r.opts.RateLimit <= 0 {
+        return true
+    }
+
+    r.limitMu.Lock()
+    defer r.limitMu.Unlock()
+
+    now := r.opts.Clock.Now()
+    r.tokens += now.Sub(r.lastFill).Seconds() * r.opts.RateLimit
+    if max := float64(r.opts.Burst); r.tokens > max {
+        r.tokens = max
+    }
+    r.lastFill = now
+
+    if r.tokens < 1 {
+        return false
+    }
+    r.tokens--
+
+    return true
+}
+
+func (r *AsyncReporter) run() {
+    defer close(r.stopped)
+
+    ticker := time.NewTicker(r.opts.FlushInterval)
+    defer ticker.Stop()
+
+    batch := make([]Error, 0, r.opts.BatchSize)
+    write := func(ctx context.Context) error {
+        if len(batch) == 0 {
+            return nil
+        }
+        err := r.sink.Write(ctx, batch)
+        if err != nil && r.opts.OnError != nil {
+            r.opts.OnError(err)
+        }
+        batch = make([]Error, 0, r.opts.BatchSize)
+
+        return err
+    }
+    drain := func(ctx context.Context) error {
+        var lastErr error
+        for {
+            select {
+            case err := <-r.queue:
+                batch = append(batch, err)
+                if len(batch) >= r.opts.BatchSize {
+                    if err := write(ctx); err != nil {
+                        lastErr = err
+                    }
+                }
+            default:
+                if err := write(ctx); err != nil {
+                    lastErr = err
+                }
+                return lastErr
+            }
+        }
+    }
+
+    for {
+        select {
+        case err := <-r.queue:
+            batch = append(batch, err)
+            if len(batch) >= r.opts.BatchSize {
+                _ = write(context.Background())
+            }
+        case <-ticker.C:
+            _ = write(context.Background())
+        case req := <-r.flushc:
+            req.reply <- drain(req.ctx)
+        case <-r.done:
+            _ = drain(context.Background())
+            return
+        }
+    }
+}
+
+// WriterSink writes errors to an io.Writer, one JSON document per line.
+type WriterSink struct {
+    mu sync.Mutex
+    w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+    return &WriterSink{w: w}
+}
+
+// StderrSink writes errors to the standard error.
+func StderrSink() *WriterSink {
+    return NewWriterSink(os.Stderr)
+}
+
+func (s *WriterSink) Write(_ context.Context, errs []Error) error {
+    buf := &bytes.Buffer{}
+    enc := json.NewEncoder(buf)
+    for _, err := range errs {
+        if err := enc.Encode(err.JSON()); err != nil {
+            return err
+        }
+    }
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    _, err := s.w.Write(buf.Bytes())
+
+    return err
+}
+
+// FileSink appends errors to a file, one JSON document per line.
+type FileSink struct {
+    *WriterSink
+    f *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+    if err != nil {
+        return nil, err
+    }
+
+    return &FileSink{WriterSink: NewWriterSink(f), f: f}, nil
+}
+
+func (s *FileSink) Close() error {
+    return s.f.Close()
+}
+
+// WebhookSink posts each batch of errors to a URL as a JSON array.
+type WebhookSink struct {
+    URL    string
+    Client *http.Client
+    Header http.Header
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+    return &WebhookSink{
+        URL:    url,
+        Client: &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+func (s *WebhookSink) Write(ctx context.Context, errs []Error) error {
+    jsonErrs := make([]*JSONError, len(errs))
+    for i, err := range errs {
+        jsonErrs[i] = err.JSON()
+    }
+
+    body, err := json.Marshal(jsonErrs)
+    if err != nil {
+        return err
+    }
+
+    req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req = req.WithContext(ctx)
+    for key, values := range s.Header {
+        req.Header[key] = values
+    }
+    req.Header.Set("Content-Type", "application/json")
+
+    client := s.Client
+    if client == nil {
+        client = http.DefaultClient
+    }
+
+    res, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer res.Body.Close()
+    _, _ = io.Copy(ioutil.Discard, res.Body)
+
+    if res.StatusCode < 200 || res.StatusCode > 299 {
+        return fmt.Errorf("errors: webhook %s responded %s", s.URL, res.Status)
+    }
+
+    return nil
+}
+
+// MemorySink keeps the errors written to it, for tests.
+type MemorySink struct {
+    mu   sync.Mutex
+    errs []Error
+}
+
+func (s *MemorySink) Write(_ context.Context, errs []Error) error {
+    s.mu.Lock()
+    s.errs = append(s.errs, errs...)
+    s.mu.Unlock()
+
+    return nil
+}
+
+func (s *MemorySink) Errors() []Error {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    return append([]Error(nil), s.errs...)
+}
+
+func (s *MemorySink) Reset() {
+    s.mu.Lock()
+    s.errs = nil
+    s.mu.Unlock()
+}
@@ -0,0 +1,181 @@
+package errors
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "io/ioutil"
+    "net/http"
+    "net/http/httptest"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/require"
+)
+
+type blockingSink struct {
+    MemorySink
+    release chan struct{}
+}
+
+func (s *blockingSink) Write(ctx context.Context, errs []Error) error {
+    <-s.release
+    return s.MemorySink.Write(ctx, errs)
+}
+
+func codes(errs []Error) []string {
+    result := make([]string, len(errs))
+    for i, err := range errs {
+        result[i] = err.GetCode()
+    }
+
+    return result
+}
+
+func TestAsyncReporter(t *testing.T) {
+    sink := &MemorySink{}
+    r := NewAsyncReporter(sink, ReporterOptions{
+        Filter: FilterTypes(InternalErrorType, TimeoutType),
+    })
+
+    var reporter Reporter = r
+    reporter.Report(InternalError("code1", "err1"))
+    reporter.Report(NotFound("code2", "err2"))
+    reporter.Report(Timeout("code3", "err3"))
+    reporter.Report(nil)
+
+    require.NoError(t, reporter.Flush(context.Background()))
+    require.Equal(t, []string{"code1", "code3"}, codes(sink.Errors()))
+
+    r.Report(InternalError("code4", "err4"))
+    require.NoError(t, r.Close(context.Background()))
+    require.Equal(t, []string{"code1", "code3", "code4"}, codes(sink.Errors()))
+
+    r.Report(InternalError("code5", "err5"))
+    require.NoError(t, r.Flush(context.Background()))
+    require.Equal(t, uint64(1), r.Dropped())
+}
+
+func TestAsyncReporterDropPolicy(t *testing.T) {
+    for _, tc := range []struct {
+        policy DropPolicy
+        want   []string
+    }{
+        {DropNewest, []string{"code0", "code1", "code2"}},
+        {DropOldest, []string{"code0", "code3", "code4"}},
+    } {
+        sink := &blockingSink{release: make(chan struct{})}
+        r := NewAsyncReporter(sink, ReporterOptions{QueueSize: 2, BatchSize: 1, DropPolicy: tc.policy})
+
+        r.Report(NewWithCode("code0", "err0"))
+        require.Eventually(t, func() bool { return len(r.queue) == 0 }, time.Second, time.Millisecond)
+        for _, code := range []string{"code1", "code2", "code3", "code4"} {
+            r.Report(NewWithCode(code, "err"))
+        }
+        close(sink.release)
+
+        require.NoError(t, r.Close(context.Background()))
+        require.Equal(t, tc.want, codes(sink.Errors()))
+        require.Equal(t, uint64(2), r.Dropped())
+    }
+}
+
+func TestAsyncReporterBlockClose(t *testing.T) {
+    sink := &blockingSink{release: make(chan struct{})}
+    defer close(sink.release)
+    r := NewAsyncReporter(sink, ReporterOptions{QueueSize: 1, BatchSize: 1, DropPolicy: Block})
+
+    r.Report(NewWithCode("code0", "err0"))
+    require.Eventually(t, func() bool { return len(r.queue) == 0 }, time.Second, time.Millisecond)
+    r.Report(NewWithCode("code1", "err1"))
+
+    reported := make(chan struct{})
+    go func() {
+        r.Report(NewWithCode("code2", "err2"))
+        close(reported)
+    }()
+
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+    require.Equal(t, context.DeadlineExceeded, r.Close(ctx))
+
+    select {
+    case <-reported:
+    case <-time.After(time.Second):
+        t.Fatal("Report still blocked after Close")
+    }
+    require.Equal(t, uint64(1), r.Dropped())
+}
+
+func TestAsyncReporterRateLimit(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    sink := &MemorySink{}
+    r := NewAsyncReporter(sink, ReporterOptions{RateLimit: 1, Burst: 2, Clock: clock, Filter: FilterPanic})
+    defer r.Close(context.Background())
+
+    for i := 0; i < 4; i++ {
+        r.Report(InternalError("code1", "err1").WithPanic())
+    }
+    r.Report(InternalError("code2", "err2"))
+    clock.Add(time.Second)
+    r.Report(InternalError("code3", "err3").WithPanic())
+
+    require.NoError(t, r.Flush(context.Background()))
+    require.Equal(t, []string{"code1", "code1", "code3"}, codes(sink.Errors()))
+    require.Equal(t, uint64(2), r.Dropped())
+}
+
+func TestWriterSink(t *testing.T) {
+    buf := &bytes.Buffer{}
+    require.NoError(t, NewWriterSink(buf).Write(context.Background(), []Error{x().(Error), NotFound("code3", "err3")}))
+
+    lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+    require.Len(t, lines, 2)
+    jsonErr := &JSONError{}
+    require.NoError(t, json.Unmarshal([]byte(lines[1]), jsonErr))
+    require.Equal(t, "code3", jsonErr.Code)
+
+    path := filepath.Join(t.TempDir(), "errors.log")
+    sink, err := NewFileSink(path)
+    require.NoError(t, err)
+    require.NoError(t, sink.Write(context.Background(), []Error{NotFound("code3", "err3")}))
+    require.NoError(t, sink.Close())
+    b, err := ioutil.ReadFile(path)
+    require.NoError(t, err)
+    require.Contains(t, string(b), `"code":"code3"`)
+}
+
+type webhookRequest struct {
+    header http.Header
+    body   []byte
+}
+
+func TestWebhookSink(t *testing.T) {
+    requests := make(chan webhookRequest, 2)
+    statuses := make(chan int, 2)
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        body, _ := ioutil.ReadAll(r.Body)
+        requests <- webhookRequest{header: r.Header, body: body}
+        w.WriteHeader(<-statuses)
+    }))
+    defer srv.Close()
+
+    sink := NewWebhookSink(srv.URL)
+    sink.Header = http.Header{"X-Token": {"secret"}}
+
+    statuses <- http.StatusOK
+    require.NoError(t, sink.Write(context.Background(), []Error{NotFound("code3", "err3")}))
+    req := <-requests
+    require.Equal(t, "application/json", req.header.Get("Content-Type"))
+    require.Equal(t, "secret", req.header.Get("X-Token"))
+    var received []*JSONError
+    require.NoError(t, json.Unmarshal(req.body, &received))
+    require.Len(t, received, 1)
+    require.Equal(t, "code3", received[0].Code)
+
+    statuses <- http.StatusBadGateway
+    require.Error(t, sink.Write(context.Background(), []Error{NotFound("code3", "err3")}))
+    <-requests
+}
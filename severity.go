@@ -0,0 +1,112 @@
+package errors
+
+import "fmt"
+
+// Severity tells how serious an error is, independently of its type.
+type Severity int
+
+// The zero Severity is unset: the severity is then derived from the type.
+const (
+    SeverityDebug Severity = iota + 1
+    SeverityInfo
+    SeverityWarning
+    SeverityError
+    SeverityCritical
+    SeverityFatal
+)
+
+var severityNames = map[Severity]string{
+    SeverityDebug:    "debug",
+    SeverityInfo:     "info",
+    SeverityWarning:  "warning",
+    SeverityError:    "error",
+    SeverityCritical: "critical",
+    SeverityFatal:    "fatal",
+}
+
+func (s Severity) String() string {
+    if name, ok := severityNames[s]; ok {
+        return name
+    }
+
+    return ""
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+    return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes an unknown name, e.g. one added by a newer version of
+// this package, as the zero Severity rather than failing the whole document.
+func (s *Severity) UnmarshalText(text []byte) error {
+    *s, _ = ParseSeverity(string(text))
+
+    return nil
+}
+
+// ParseSeverity returns the Severity with the given name. The empty name is
+// the zero Severity.
+func ParseSeverity(name string) (Severity, error) {
+    if name == "" {
+        return 0, nil
+    }
+    for severity, severityName := range severityNames {
+        if severityName == name {
+            return severity, nil
+        }
+    }
+
+    return 0, fmt.Errorf("errors: unknown severity %q", name)
+}
+
+// DefaultSeverity is the severity of an error of the given type: warning for
+// errors caused by the client, error otherwise.
+func DefaultSeverity(errType string) Severity {
+    switch errType {
+    case BadRequestType, UnauthorizedType, ForbiddenType, NotFoundType, ConflictType:
+        return SeverityWarning
+    }
+
+    return SeverityError
+}
+
+func (e *GenericError) WithSeverity(severity Severity) Error {
+    e.severity = severity
+
+    return e
+}
+
+// defaultSeverityOf returns the default severity of the type of err. Errors
+// raised by a panic are at least critical.
+func defaultSeverityOf(err Error) Severity {
+    severity := DefaultSeverity(err.GetType())
+    if err.IsPanic() && severity < SeverityCritical {
+        severity = SeverityCritical
+    }
+
+    return severity
+}
+
+// GetSeverity returns the severity set with WithSeverity on the outermost
+// error of the chain that has one. Without any, it is the highest default
+// severity of the error and its causes.
+func (e *GenericError) GetSeverity() Severity {
+    var severity Severity
+    for _, cerr := range chain(e) {
+        if ge, ok := cerr.(*GenericError); ok && ge.severity != 0 {
+            return ge.severity
+        }
+        if s := defaultSeverityOf(cerr); s > severity {
+            severity = s
+        }
+    }
+
+    return severity
+}
+
+// FilterSeverity selects the errors of severity min or higher.
+func FilterSeverity(min Severity) func(err Error) bool {
+    return func(err Error) bool {
+        return err.GetSeverity() >= min
+    }
+}
@@ -0,0 +1,59 @@
+package errors
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func TestSeverity(t *testing.T) {
+    require.Equal(t, SeverityWarning, BadRequest("code1", "err1").GetSeverity())
+    require.Equal(t, SeverityWarning, NotFound("code1", "err1").GetSeverity())
+    require.Equal(t, SeverityError, InternalError("code1", "err1").GetSeverity())
+    require.Equal(t, SeverityError, New("err1").GetSeverity())
+    require.Equal(t, SeverityCritical, BadRequest("code1", "err1").WithPanic().GetSeverity())
+    require.Equal(t, SeverityFatal, BadRequest("code1", "err1").WithPanic().WithSeverity(SeverityFatal).GetSeverity())
+    require.Equal(t, SeverityInfo, NotFound("code1", "err1").WithSeverity(SeverityInfo).GetSeverity())
+
+    err := NotFound("code2", "err2").WithCause(InternalError("code1", "err1").WithSeverity(SeverityCritical))
+    require.Equal(t, SeverityCritical, err.GetSeverity())
+    require.Equal(t, SeverityWarning, Wrap(err, "loading").WithSeverity(SeverityWarning).GetSeverity())
+    require.Equal(t, SeverityInfo, InternalError("code3", "err3").WithPanic().WithSeverity(SeverityInfo).GetSeverity())
+    require.Equal(t, SeverityCritical, NotFound("code3", "err3").WithCause(InternalError("code1", "err1").WithPanic()).GetSeverity())
+    require.True(t, FilterSeverity(SeverityError)(err))
+    require.False(t, FilterSeverity(SeverityError)(NotFound("code1", "err1")))
+
+    newErr := ParseJSONError(err.JSON())
+    require.Equal(t, SeverityCritical, newErr.GetSeverity())
+
+    b, jerr := json.Marshal(err)
+    require.NoError(t, jerr)
+    require.Contains(t, string(b), `"severity":"critical"`)
+
+    b, _ = err.(*GenericError).MarshalBinary()
+    decoded := &GenericError{}
+    require.NoError(t, decoded.UnmarshalBinary(b))
+    require.Equal(t, SeverityCritical, decoded.GetSeverity())
+}
+
+func TestParseSeverity(t *testing.T) {
+    for severity := SeverityDebug; severity <= SeverityFatal; severity++ {
+        parsed, err := ParseSeverity(severity.String())
+        require.NoError(t, err)
+        require.Equal(t, severity, parsed)
+    }
+
+    _, err := ParseSeverity("loud")
+    require.Error(t, err)
+
+    var severity Severity = SeverityFatal
+    require.NoError(t, severity.UnmarshalText([]byte("loud")))
+    require.Equal(t, Severity(0), severity)
+
+    jsonErr := &JSONError{}
+    require.NoError(t, json.Unmarshal([]byte(`{"code":"code1","message":"err1","errType":"NotFound","severity":"notice"}`), jsonErr))
+    newErr := ParseJSONError(jsonErr)
+    require.Equal(t, "code1", newErr.GetCode())
+    require.Equal(t, SeverityWarning, newErr.GetSeverity())
+}
@@ -0,0 +1,172 @@
+// Package sqlerr translates the errors of database/sql and its drivers into
+// errors.Error, without depending on any driver.
+//
+// PostgreSQL errors, of pgx and lib/pq, are recognised by their SQLState
+// method and MySQL errors by their Number field. As the MySQL driver is not
+// imported, its errors are matched by type name: any struct named MySQLError
+// with a uint16 Number field, from whatever package, is taken for one.
+package sqlerr
+
+import (
+    "context"
+    "database/sql"
+    stderrors "errors"
+    "reflect"
+    "strings"
+
+    "github.com/onedaycat/errors"
+)
+
+// Fields set on translated errors.
+const (
+    SQLStateField  = "sqlstate"
+    RetryableField = "retryable"
+)
+
+var (
+    ErrNoRows               = errors.DefNotFound("NoRows", "record not found")
+    ErrUniqueViolation      = errors.DefConflict("UniqueViolation", "record already exists")
+    ErrForeignKeyViolation  = errors.DefBadRequest("ForeignKeyViolation", "referenced record does not exist")
+    ErrDeadlock             = errors.DefInternalError("Deadlock", "deadlock detected")
+    ErrSerializationFailure = errors.DefInternalError("SerializationFailure", "could not serialize access")
+    ErrQueryTimeout         = errors.DefTimeout("QueryTimeout", "query timed out")
+    ErrDatabase             = errors.DefInternalError("DatabaseError", "database error")
+)
+
+type kind int
+
+const (
+    kindOther kind = iota
+    kindUnique
+    kindForeignKey
+    kindDeadlock
+    kindSerialization
+    kindTimeout
+)
+
+// PostgreSQL SQLSTATE codes. 57014 is also raised by statement_timeout.
+var pgKinds = map[string]kind{
+    "23505": kindUnique,
+    "23503": kindForeignKey,
+    "40P01": kindDeadlock,
+    "40001": kindSerialization,
+    "57014": kindTimeout,
+}
+
+// MySQL error numbers. 1205 is a lock wait timeout.
+var mysqlKinds = map[uint16]kind{
+    1062: kindUnique,
+    1451: kindForeignKey,
+    1452: kindForeignKey,
+    1213: kindDeadlock,
+    1205: kindTimeout,
+}
+
+type sqlStater interface {
+    SQLState() string
+}
+
+type timeouter interface {
+    Timeout() bool
+}
+
+// Translate returns the errors.Error matching a database error, with err as
+// its cause and the SQLSTATE, if known, as a field. Deadlocks and
+// serialization failures are marked retryable. Translate returns nil for a
+// nil err and errors.Error values unchanged.
+func Translate(err error) errors.Error {
+    if err == nil {
+        return nil
+    }
+    if xerr, ok := err.(errors.Error); ok {
+        return xerr
+    }
+
+    var xerr errors.Error
+    state, kind := classify(err)
+    switch {
+    case stderrors.Is(err, sql.ErrNoRows):
+        xerr = ErrNoRows.New()
+    case kind == kindUnique:
+        xerr = ErrUniqueViolation.New()
+    case kind == kindForeignKey:
+        xerr = ErrForeignKeyViolation.New()
+    case kind == kindDeadlock:
+        xerr = ErrDeadlock.New().WithField(RetryableField, true)
+    case kind == kindSerialization:
+        xerr = ErrSerializationFailure.New().WithField(RetryableField, true)
+    case kind == kindTimeout, stderrors.Is(err, context.DeadlineExceeded), isTimeout(err):
+        xerr = ErrQueryTimeout.New()
+    default:
+        xerr = ErrDatabase.New()
+    }
+    if state != "" {
+        xerr.WithField(SQLStateField, state)
+    }
+
+    return xerr.WithCause(err)
+}
+
+// IsRetryable tells if err was translated from a transient failure, such as
+// a deadlock, after which the transaction can be retried.
+func IsRetryable(err error) bool {
+    xerr, ok := err.(errors.Error)
+    if !ok {
+        return false
+    }
+    retryable, _ := xerr.GetAllFields()[RetryableField].(bool)
+
+    return retryable
+}
+
+// SQLState returns the SQLSTATE of the first driver error in the chain of
+// err, or "" if there is none.
+func SQLState(err error) string {
+    state, _ := classify(err)
+
+    return state
+}
+
+// classify finds the first driver error in the chain of err.
+func classify(err error) (string, kind) {
+    for ; err != nil; err = stderrors.Unwrap(err) {
+        if e, ok := err.(sqlStater); ok {
+            state := e.SQLState()
+            return state, pgKinds[state]
+        }
+        if v, ok := mysqlError(err); ok {
+            var state string
+            if f := v.FieldByName("SQLState"); f.Kind() == reflect.Array && f.Type().Elem().Kind() == reflect.Uint8 {
+                b := make([]byte, f.Len())
+                reflect.Copy(reflect.ValueOf(b), f)
+                state = strings.TrimRight(string(b), "\x00")
+            }
+            return state, mysqlKinds[uint16(v.FieldByName("Number").Uint())]
+        }
+    }
+
+    return "", kindOther
+}
+
+// mysqlError returns the struct of a *mysql.MySQLError, found by name to
+// avoid depending on the driver.
+func mysqlError(err error) (reflect.Value, bool) {
+    v := reflect.ValueOf(err)
+    if v.Kind() == reflect.Ptr {
+        v = v.Elem()
+    }
+    if v.Kind() != reflect.Struct || v.Type().Name() != "MySQLError" {
+        return reflect.Value{}, false
+    }
+    if v.FieldByName("Number").Kind() != reflect.Uint16 {
+        return reflect.Value{}, false
+    }
+
+    return v, true
+}
+
+func isTimeout(err error) bool {
+    var e timeouter
+
+    return stderrors.As(err, &e) && e.Timeout()
+}
@@ -0,0 +1,89 @@
+package sqlerr
+
+import (
+    "context"
+    "database/sql"
+    "testing"
+
+    "github.com/go-sql-driver/mysql"
+    "github.com/onedaycat/errors"
+    "github.com/stretchr/testify/require"
+)
+
+// PgError mimics pgconn.PgError.
+type PgError struct {
+    Code    string
+    Message string
+}
+
+func (e *PgError) Error() string    { return "ERROR: " + e.Message + " (SQLSTATE " + e.Code + ")" }
+func (e *PgError) SQLState() string { return e.Code }
+
+type wrapper struct {
+    err error
+}
+
+func (w *wrapper) Error() string { return "query: " + w.err.Error() }
+func (w *wrapper) Unwrap() error { return w.err }
+
+// noRows matches sql.ErrNoRows with an Is method rather than by identity.
+type noRows struct{}
+
+func (noRows) Error() string        { return "no rows" }
+func (noRows) Is(target error) bool { return target == sql.ErrNoRows }
+
+type netTimeout struct{}
+
+func (netTimeout) Error() string   { return "i/o timeout" }
+func (netTimeout) Timeout() bool   { return true }
+func (netTimeout) Temporary() bool { return true }
+
+func TestTranslate(t *testing.T) {
+    require.Nil(t, Translate(nil))
+
+    for _, tc := range []struct {
+        err       error
+        def       *errors.ErrorDefinition
+        state     string
+        retryable bool
+    }{
+        {sql.ErrNoRows, ErrNoRows, "", false},
+        {&wrapper{sql.ErrNoRows}, ErrNoRows, "", false},
+        {&wrapper{noRows{}}, ErrNoRows, "", false},
+        {&PgError{Code: "23505", Message: "duplicate key"}, ErrUniqueViolation, "23505", false},
+        {&wrapper{&PgError{Code: "23503", Message: "fk"}}, ErrForeignKeyViolation, "23503", false},
+        {&PgError{Code: "40P01", Message: "deadlock"}, ErrDeadlock, "40P01", true},
+        {&PgError{Code: "40001", Message: "serialization"}, ErrSerializationFailure, "40001", true},
+        {&PgError{Code: "57014", Message: "canceled"}, ErrQueryTimeout, "57014", false},
+        {&PgError{Code: "42P01", Message: "no table"}, ErrDatabase, "42P01", false},
+        {&mysql.MySQLError{Number: 1062, SQLState: [5]byte{'2', '3', '0', '0', '0'}, Message: "duplicate"}, ErrUniqueViolation, "23000", false},
+        {&mysql.MySQLError{Number: 1452, Message: "fk"}, ErrForeignKeyViolation, "", false},
+        {&mysql.MySQLError{Number: 1213, SQLState: [5]byte{'4', '0', '0', '0', '1'}, Message: "deadlock"}, ErrDeadlock, "40001", true},
+        {context.DeadlineExceeded, ErrQueryTimeout, "", false},
+        {&wrapper{netTimeout{}}, ErrQueryTimeout, "", false},
+        {sql.ErrConnDone, ErrDatabase, "", false},
+    } {
+        err := Translate(tc.err)
+        require.True(t, tc.def.Is(err), "%v: got %v", tc.err, err)
+        require.Equal(t, tc.def.Type, err.GetType())
+        require.Equal(t, tc.retryable, IsRetryable(err), "%v", tc.err)
+        require.Equal(t, tc.err.Error(), err.Unwrap().Error())
+        if tc.state == "" {
+            require.NotContains(t, err.GetFields(), SQLStateField)
+        } else {
+            require.Equal(t, tc.state, err.GetFields()[SQLStateField])
+        }
+    }
+
+    require.Equal(t, errors.ConflictType, Translate(&PgError{Code: "23505"}).GetType())
+
+    xerr := errors.NotFound("code1", "err1")
+    require.Equal(t, xerr, Translate(xerr))
+    require.False(t, IsRetryable(sql.ErrNoRows))
+}
+
+func TestSQLState(t *testing.T) {
+    require.Equal(t, "", SQLState(nil))
+    require.Equal(t, "", SQLState(sql.ErrNoRows))
+    require.Equal(t, "23505", SQLState(&wrapper{&PgError{Code: "23505"}}))
+}
@@ -2,16 +2,138 @@ package errors
 
 import (
     "fmt"
+    "io"
     "runtime"
     "strings"
+    "sync/atomic"
 )
 
 type Stacktrace []*StacktraceFrame
 
+// Caller returns the innermost frame, or nil if the stacktrace is empty.
 func (f Stacktrace) Caller() *StacktraceFrame {
-    return f[len(f)-1:][0]
+    if len(f) == 0 {
+        return nil
+    }
+
+    return f[len(f)-1]
+}
+
+// At returns the frame at index i, counting from the outermost frame, or nil
+// if i is out of range.
+func (f Stacktrace) At(i int) *StacktraceFrame {
+    if i < 0 || i >= len(f) {
+        return nil
+    }
+
+    return f[i]
+}
+
+// Filter returns the frames for which keep returns true.
+func (f Stacktrace) Filter(keep func(frame *StacktraceFrame) bool) Stacktrace {
+    var frames Stacktrace
+    for _, frame := range f {
+        if keep(frame) {
+            frames = append(frames, frame)
+        }
+    }
+
+    return frames
+}
+
+// TrimRuntime removes the frames of the runtime package and its subpackages.
+func (f Stacktrace) TrimRuntime() Stacktrace {
+    return f.Filter(func(frame *StacktraceFrame) bool {
+        return frame.Module != "runtime" && !strings.HasPrefix(frame.Module, "runtime/")
+    })
+}
+
+// InApp returns the frames whose package path starts with one of prefixes.
+// Without prefixes, it returns the frames outside the standard library.
+func (f Stacktrace) InApp(prefixes ...string) Stacktrace {
+    if len(prefixes) == 0 {
+        return f.Filter(isInApp)
+    }
+
+    return f.Filter(func(frame *StacktraceFrame) bool {
+        for _, prefix := range prefixes {
+            if strings.HasPrefix(frame.Module, prefix) {
+                return true
+            }
+        }
+
+        return false
+    })
+}
+
+// Top returns the n innermost frames.
+func (f Stacktrace) Top(n int) Stacktrace {
+    if n <= 0 {
+        return nil
+    }
+    if n >= len(f) {
+        return f
+    }
+
+    return f[len(f)-n:]
+}
+
+// CommonSuffix returns the outermost frames f and other have in common, that
+// is the callers they share. It is the common suffix of the stacks read from
+// the innermost frame, as runtime prints them, and so the common start of the
+// slices, which hold the outermost frame first.
+func (f Stacktrace) CommonSuffix(other Stacktrace) Stacktrace {
+    n := 0
+    for n < len(f) && n < len(other) && f[n].Equal(other[n]) {
+        n++
+    }
+    if n == 0 {
+        return nil
+    }
+
+    return f[:n]
+}
+
+// Equal reports whether f and other hold the same frames.
+func (f Stacktrace) Equal(other Stacktrace) bool {
+    if len(f) != len(other) {
+        return false
+    }
+    for i := range f {
+        if !f[i].Equal(other[i]) {
+            return false
+        }
+    }
+
+    return true
 }
 
+// Frames returns an iterator over the frames, innermost first.
+func (f Stacktrace) Frames() *Frames {
+    return &Frames{stack: f, next: len(f) - 1}
+}
+
+// Frames iterates over a Stacktrace, like runtime.Frames.
+type Frames struct {
+    stack Stacktrace
+    next  int
+}
+
+// Next returns the next frame, innermost first, and whether more frames
+// follow it. It returns a nil frame once the frames are exhausted.
+func (fr *Frames) Next() (frame *StacktraceFrame, more bool) {
+    if fr.next < 0 {
+        return nil, false
+    }
+
+    frame = fr.stack[fr.next]
+    fr.next--
+
+    return frame, fr.next >= 0
+}
+
+// String writes one frame per line, oldest first, whatever the Formatter set
+// with SetFormatter.
 func (f Stacktrace) String() string {
     sb := &strings.Builder{}
     for _, frame := range f {
@@ -21,6 +143,19 @@ func (f Stacktrace) String() string {
     return sb.String()
 }
 
+// Format implements fmt.Formatter: %+v lays out the stacktrace with the
+// Formatter set with SetFormatter, and the other verbs use String.
+func (f Stacktrace) Format(s fmt.State, verb rune) {
+    switch {
+    case verb == 'v' && s.Flag('+'):
+        currentFormatter().FormatStack(s, f)
+    case verb == 'v' && s.Flag('#'):
+        _, _ = fmt.Fprintf(s, "%#v", []*StacktraceFrame(f))
+    default:
+        _, _ = io.WriteString(s, f.String())
+    }
+}
+
 func (f Stacktrace) Strings() []string {
     strs := make([]string, len(f))
     for i, frame := range f {
@@ -38,11 +173,43 @@ type StacktraceFrame struct {
     Lineno int `json:"lineno,omitempty"`
 }
 
+func (sf *StacktraceFrame) Equal(other *StacktraceFrame) bool {
+    if sf == nil || other == nil {
+        return sf == other
+    }
+
+    return sf.Filename == other.Filename &&
+        sf.Function == other.Function &&
+        sf.Module == other.Module &&
+        sf.Lineno == other.Lineno
+}
+
 func (sf *StacktraceFrame) String() string {
     return fmt.Sprintf("%s %s:%d", sf.Function, sf.Filename, sf.Lineno)
 }
 
+// A StacktraceFunc captures the stack skip frames above the caller of
+// NewStacktrace, oldest frame first.
+type StacktraceFunc func(skip int) Stacktrace
+
+type stacktraceFuncHolder struct {
+    f StacktraceFunc
+}
+
+var stacktraceFunc atomic.Value
+
+// SetStacktraceFunc replaces the capture of the stacktraces of new errors,
+// e.g. with a fixed stack for deterministic test output. nil restores the
+// capture of the goroutine stack.
+func SetStacktraceFunc(f StacktraceFunc) {
+    stacktraceFunc.Store(stacktraceFuncHolder{f})
+}
+
 func NewStacktrace(skip int) Stacktrace {
+    if holder, ok := stacktraceFunc.Load().(stacktraceFuncHolder); ok && holder.f != nil {
+        return holder.f(skip)
+    }
+
     var frames []*StacktraceFrame
 
     callerPcs := make([]uintptr, 50)
@@ -0,0 +1,74 @@
+package errors
+
+import (
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func testStack() Stacktrace {
+    return Stacktrace{
+        {Function: "goexit", Module: "runtime", Filename: "/go/src/runtime/asm.s", Lineno: 1},
+        {Function: "main", Module: "main", Filename: "/app/main.go", Lineno: 10},
+        {Function: "Do", Module: "github.com/app/svc", Filename: "/app/svc/svc.go", Lineno: 20},
+        {Function: "Get", Module: "github.com/lib/db", Filename: "/lib/db/db.go", Lineno: 30},
+        {Function: "Read", Module: "os", Filename: "/go/src/os/file.go", Lineno: 40},
+    }
+}
+
+func functions(stack Stacktrace) []string {
+    names := make([]string, len(stack))
+    for i, frame := range stack {
+        names[i] = frame.Function
+    }
+
+    return names
+}
+
+func TestStacktraceAccessors(t *testing.T) {
+    stack := testStack()
+    require.Equal(t, "Read", stack.Caller().Function)
+    require.Equal(t, "main", stack.At(1).Function)
+    require.Nil(t, stack.At(5))
+    require.Nil(t, stack.At(-1))
+
+    var empty Stacktrace
+    require.Nil(t, empty.Caller())
+    require.Nil(t, empty.At(0))
+    frame, more := empty.Frames().Next()
+    require.Nil(t, frame)
+    require.False(t, more)
+
+    var names []string
+    frames := stack.Frames()
+    for {
+        frame, more := frames.Next()
+        names = append(names, frame.Function)
+        if !more {
+            break
+        }
+    }
+    require.Equal(t, []string{"Read", "Get", "Do", "main", "goexit"}, names)
+}
+
+func TestStacktraceFilter(t *testing.T) {
+    stack := testStack()
+    require.Equal(t, []string{"main", "Do", "Get", "Read"}, functions(stack.TrimRuntime()))
+    require.Equal(t, []string{"main", "Do", "Get"}, functions(stack.InApp()))
+    require.Equal(t, []string{"Do"}, functions(stack.InApp("github.com/app/")))
+    require.Equal(t, []string{"Get", "Read"}, functions(stack.Top(2)))
+    require.Len(t, stack.Top(10), 5)
+    require.Nil(t, stack.Top(0))
+    require.Len(t, stack, 5)
+}
+
+func TestStacktraceCompare(t *testing.T) {
+    stack := testStack()
+    require.True(t, stack.Equal(testStack()))
+    require.False(t, stack.Equal(stack.Top(2)))
+
+    other := append(testStack()[:3], &StacktraceFrame{Function: "Put", Module: "github.com/lib/db", Filename: "/lib/db/db.go", Lineno: 50})
+    require.False(t, stack.Equal(other))
+    require.Equal(t, []string{"goexit", "main", "Do"}, functions(stack.CommonSuffix(other)))
+    require.Nil(t, stack.CommonSuffix(nil))
+}
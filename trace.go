@@ -0,0 +1,88 @@
+package errors
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// A TraceExtractor returns the IDs of the span active in ctx, or empty
+// strings if there is none.
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+var (
+    traceExtractorMu sync.RWMutex
+    traceExtractor   TraceExtractor
+)
+
+// SetTraceExtractor registers the function WithContext uses to find the active
+// span. With OpenTelemetry it would read trace.SpanContextFromContext(ctx).
+func SetTraceExtractor(fn TraceExtractor) {
+    traceExtractorMu.Lock()
+    traceExtractor = fn
+    traceExtractorMu.Unlock()
+}
+
+func (e *GenericError) GetTraceID() string {
+    return e.traceID
+}
+
+func (e *GenericError) GetSpanID() string {
+    return e.spanID
+}
+
+// Span is the part of a tracing span RecordError writes to. Tracing libraries
+// are adapted with a small wrapper; for an OpenTelemetry trace.Span, SetError
+// calls SetStatus(codes.Error, description) and the attribute maps become
+// attribute.String key-values.
+type Span interface {
+    SetError(description string)
+    AddEvent(name string, attributes map[string]string)
+    SetAttributes(attributes map[string]string)
+}
+
+// RecordError marks span as failed and adds an "exception" event describing
+// err and its causes. Inputs and fields are set as span attributes. A nil err
+// is ignored.
+func RecordError(span Span, err error) {
+    if span == nil || err == nil {
+        return
+    }
+
+    xerr := asError(err)
+
+    span.SetError(xerr.Error())
+
+    causes := make([]string, 0, 4)
+    for _, cerr := range chain(xerr)[1:] {
+        causes = append(causes, layerString(cerr))
+    }
+
+    event := map[string]string{
+        "exception.type":    xerr.GetType(),
+        "exception.code":    xerr.GetCode(),
+        "exception.message": xerr.GetMessage(),
+    }
+    if stack := xerr.GetStacktrace(); stack != nil {
+        event["exception.stacktrace"] = stack.String()
+    }
+    if len(causes) > 0 {
+        event["exception.cause"] = strings.Join(causes, "\n")
+    }
+    span.AddEvent("exception", event)
+
+    attributes := map[string]string{
+        "error.type":     xerr.GetType(),
+        "error.code":     xerr.GetCode(),
+        "error.severity": xerr.GetSeverity().String(),
+    }
+    for i, input := range xerr.GetAllInputs() {
+        attributes["error.input."+strconv.Itoa(i)] = fmt.Sprintf("%+v", input)
+    }
+    for key, value := range xerr.GetAllFields() {
+        attributes["error.field."+key] = fmt.Sprintf("%+v", value)
+    }
+    span.SetAttributes(attributes)
+}
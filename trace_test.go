@@ -0,0 +1,75 @@
+package errors
+
+import (
+    "context"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+type spanKey struct{}
+
+type fakeSpan struct {
+    status     string
+    events     map[string]map[string]string
+    attributes map[string]string
+}
+
+func (s *fakeSpan) SetError(description string) {
+    s.status = description
+}
+
+func (s *fakeSpan) AddEvent(name string, attributes map[string]string) {
+    if s.events == nil {
+        s.events = make(map[string]map[string]string)
+    }
+    s.events[name] = attributes
+}
+
+func (s *fakeSpan) SetAttributes(attributes map[string]string) {
+    s.attributes = attributes
+}
+
+func TestWithContext(t *testing.T) {
+    defer SetTraceExtractor(nil)
+
+    ctx := context.WithValue(context.Background(), spanKey{}, [2]string{"trace1", "span1"})
+
+    err := NotFound("code1", "err1").WithContext(ctx)
+    require.Empty(t, err.GetTraceID())
+
+    SetTraceExtractor(func(ctx context.Context) (string, string) {
+        ids, _ := ctx.Value(spanKey{}).([2]string)
+        return ids[0], ids[1]
+    })
+
+    err = NotFound("code1", "err1").WithContext(ctx)
+    require.Equal(t, "trace1", err.GetTraceID())
+    require.Equal(t, "span1", err.GetSpanID())
+
+    jsonErr := err.JSON()
+    require.Equal(t, "trace1", jsonErr.TraceID)
+    require.Equal(t, "span1", ParseJSONError(jsonErr).GetSpanID())
+}
+
+func TestRecordError(t *testing.T) {
+    span := &fakeSpan{}
+    RecordError(span, x().(Error).WithInput(1))
+
+    require.Equal(t, "code2: err2", span.status)
+    event := span.events["exception"]
+    require.Equal(t, InternalErrorType, event["exception.type"])
+    require.Equal(t, "code2", event["exception.code"])
+    require.Equal(t, "err2", event["exception.message"])
+    require.Contains(t, event["exception.stacktrace"], "x\t")
+    require.Equal(t, "code1: err1\nerr0", event["exception.cause"])
+    require.Equal(t, map[string]string{
+        "error.type":     InternalErrorType,
+        "error.code":     "code2",
+        "error.severity": "error",
+        "error.input.0":  "1",
+    }, span.attributes)
+
+    RecordError(span, nil)
+    RecordError(nil, x())
+}
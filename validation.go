@@ -0,0 +1,165 @@
+package errors
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+)
+
+const (
+    ValidationCode    = "ValidationFailed"
+    ValidationMessage = "validation failed"
+)
+
+// A Violation is a field of a request that failed a validation rule.
+type Violation struct {
+    Field   string      `json:"field"`
+    Rule    string      `json:"rule,omitempty"`
+    Message string      `json:"message,omitempty"`
+    Value   interface{} `json:"value,omitempty"`
+}
+
+func (v *Violation) String() string {
+    if v.Rule == "" {
+        return fmt.Sprintf("%s: %s", v.Field, v.Message)
+    }
+
+    return fmt.Sprintf("%s: %s (%s)", v.Field, v.Message, v.Rule)
+}
+
+// ValidationError collects the violations found while validating a request,
+// to report them all at once as a single BadRequestType error.
+//
+// The zero ValidationError is ready to use.
+type ValidationError struct {
+    violations []*Violation
+}
+
+func NewValidation() *ValidationError {
+    return &ValidationError{}
+}
+
+// Add records that the field at path broke rule with value.
+func (v *ValidationError) Add(path, rule, msg string, value interface{}) *ValidationError {
+    v.violations = append(v.violations, &Violation{
+        Field:   path,
+        Rule:    rule,
+        Message: msg,
+        Value:   value,
+    })
+
+    return v
+}
+
+// Merge adds the violations of a validator run on a nested value, with their
+// paths placed under prefix.
+func (v *ValidationError) Merge(prefix string, other *ValidationError) *ValidationError {
+    if other == nil {
+        return v
+    }
+
+    for _, violation := range other.violations {
+        merged := *violation
+        merged.Field = JoinPath(prefix, violation.Field)
+        v.violations = append(v.violations, &merged)
+    }
+
+    return v
+}
+
+func (v *ValidationError) HasViolations() bool {
+    return len(v.violations) > 0
+}
+
+func (v *ValidationError) Violations() []*Violation {
+    return v.violations
+}
+
+// Err returns a BadRequestType error with code ValidationCode carrying a copy
+// of the violations, or nil if there are none. Violations added afterwards do
+// not change the error.
+func (v *ValidationError) Err() Error {
+    if !v.HasViolations() {
+        return nil
+    }
+
+    return created(&GenericError{
+        Code:       ValidationCode,
+        Message:    ValidationMessage,
+        errType:    BadRequestType,
+        violations: append([]*Violation(nil), v.violations...),
+        stacktrace: NewStacktrace(1),
+    })
+}
+
+// ErrWithCode is Err with a code and message of the caller's choice. An empty
+// code or message defaults to ValidationCode or ValidationMessage.
+func (v *ValidationError) ErrWithCode(code, msg string) Error {
+    if !v.HasViolations() {
+        return nil
+    }
+    if code == "" {
+        code = ValidationCode
+    }
+    if msg == "" {
+        msg = ValidationMessage
+    }
+
+    return created(&GenericError{
+        Code:       code,
+        Message:    msg,
+        errType:    BadRequestType,
+        violations: append([]*Violation(nil), v.violations...),
+        stacktrace: NewStacktrace(1),
+    })
+}
+
+// Path builds a field path from names and indexes, e.g. Path("items", 3,
+// "price") is "items[3].price".
+func Path(elems ...interface{}) string {
+    sb := &strings.Builder{}
+    for _, elem := range elems {
+        switch x := elem.(type) {
+        case int:
+            sb.WriteString("[" + strconv.Itoa(x) + "]")
+        case string:
+            if sb.Len() > 0 {
+                sb.WriteString(".")
+            }
+            sb.WriteString(x)
+        default:
+            if sb.Len() > 0 {
+                sb.WriteString(".")
+            }
+            _, _ = fmt.Fprint(sb, x)
+        }
+    }
+
+    return sb.String()
+}
+
+// JoinPath places the field path under prefix.
+func JoinPath(prefix, path string) string {
+    switch {
+    case prefix == "":
+        return path
+    case path == "":
+        return prefix
+    case strings.HasPrefix(path, "["):
+        return prefix + path
+    }
+
+    return prefix + "." + path
+}
+
+// GetViolations returns the violations of the error and its causes.
+func (e *GenericError) GetViolations() []*Violation {
+    var violations []*Violation
+    for _, cerr := range chain(e) {
+        if ge, ok := cerr.(*GenericError); ok {
+            violations = append(violations, ge.violations...)
+        }
+    }
+
+    return violations
+}
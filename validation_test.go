@@ -0,0 +1,70 @@
+package errors
+
+import (
+    "encoding/json"
+    "fmt"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func validateItem(price int) *ValidationError {
+    v := &ValidationError{}
+    if price <= 0 {
+        v.Add("price", "min", "must be positive", price)
+    }
+
+    return v
+}
+
+func TestValidation(t *testing.T) {
+    v := NewValidation()
+    require.Nil(t, v.Err())
+
+    v.Add("name", "required", "is required", "")
+    for i, price := range []int{10, -1, 0} {
+        v.Merge(Path("items", i), validateItem(price))
+    }
+    v.Merge("meta", NewValidation().Add("[0]", "max", "too long", "abc"))
+
+    err := v.Err()
+    require.EqualError(t, err, "ValidationFailed: validation failed")
+    require.Equal(t, BadRequestType, err.GetType())
+    require.Equal(t, []*Violation{
+        {Field: "name", Rule: "required", Message: "is required", Value: ""},
+        {Field: "items[1].price", Rule: "min", Message: "must be positive", Value: -1},
+        {Field: "items[2].price", Rule: "min", Message: "must be positive", Value: 0},
+        {Field: "meta[0]", Rule: "max", Message: "too long", Value: "abc"},
+    }, err.GetViolations())
+
+    b, jerr := json.Marshal(err)
+    require.NoError(t, jerr)
+    require.Contains(t, string(b), `"violations":[{"field":"name","rule":"required","message":"is required","value":""},`)
+
+    wrapped := InternalError("code1", "err1").WithCause(err)
+    require.Len(t, wrapped.GetViolations(), 4)
+    require.Contains(t, fmt.Sprintf("%+v", wrapped), "\n- items[1].price: must be positive (min)\n")
+
+    p := ToProblem(err)
+    b, _ = json.Marshal(p)
+    decoded := &Problem{}
+    require.NoError(t, json.Unmarshal(b, decoded))
+    require.Equal(t, "items[2].price", FromProblem(decoded).GetViolations()[2].Field)
+
+    custom := NewValidation().Add("email", "email", "is invalid", "x").ErrWithCode("InvalidUser", "invalid user")
+    require.EqualError(t, custom, "InvalidUser: invalid user")
+    require.EqualError(t, v.ErrWithCode("", ""), "ValidationFailed: validation failed")
+
+    v.Add("age", "min", "too young", 1)
+    v.Violations()[0] = &Violation{Field: "other"}
+    require.Len(t, err.GetViolations(), 4)
+    require.Equal(t, "name", err.GetViolations()[0].Field)
+}
+
+func TestPath(t *testing.T) {
+    require.Equal(t, "items[3].price", Path("items", 3, "price"))
+    require.Equal(t, "[0].name", Path(0, "name"))
+    require.Equal(t, "a.b", JoinPath("a", "b"))
+    require.Equal(t, "a[1]", JoinPath("a", "[1]"))
+    require.Equal(t, "b", JoinPath("", "b"))
+}
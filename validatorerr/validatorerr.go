@@ -0,0 +1,87 @@
+// Package validatorerr translates the errors of
+// github.com/go-playground/validator into errors.Error.
+package validatorerr
+
+import (
+    "fmt"
+    "strings"
+
+    "github.com/go-playground/validator/v10"
+    "github.com/onedaycat/errors"
+)
+
+// A Translator turns validator.ValidationErrors into a BadRequestType error
+// with one violation per failed field.
+type Translator struct {
+    // Code and Message are handed to errors.ValidationError.ErrWithCode.
+    Code    string
+    Message string
+    // Rule names the rule a field broke. Defaults to the validation tag,
+    // e.g. "required".
+    Rule func(fe validator.FieldError) string
+    // Describe explains why a field is invalid.
+    Describe func(fe validator.FieldError) string
+}
+
+var defaultTranslator = &Translator{}
+
+// Translate translates err with the default Translator.
+func Translate(err error) errors.Error {
+    return defaultTranslator.Translate(err)
+}
+
+// Translate returns a BadRequestType error listing the fields that failed
+// validation, caused by err. Errors not reporting failed fields, such as
+// validator.InvalidValidationError, become InternalErrorType errors.
+func (t *Translator) Translate(err error) errors.Error {
+    if err == nil {
+        return nil
+    }
+
+    fieldErrs, ok := err.(validator.ValidationErrors)
+    if !ok || len(fieldErrs) == 0 {
+        return errors.InternalError("InvalidValidation", err.Error()).WithCause(err)
+    }
+
+    v := errors.NewValidation()
+    for _, fe := range fieldErrs {
+        v.Add(Field(fe), t.rule(fe), t.describe(fe), fe.Value())
+    }
+
+    return v.ErrWithCode(t.Code, t.Message).WithCause(err)
+}
+
+func (t *Translator) rule(fe validator.FieldError) string {
+    if t.Rule != nil {
+        return t.Rule(fe)
+    }
+
+    return fe.Tag()
+}
+
+func (t *Translator) describe(fe validator.FieldError) string {
+    if t.Describe != nil {
+        return t.Describe(fe)
+    }
+    if fe.Param() != "" {
+        return fmt.Sprintf("failed on the '%s=%s' rule", fe.Tag(), fe.Param())
+    }
+
+    return fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+}
+
+// Field returns the path of the failed field below the validated struct,
+// e.g. "Items[3].Price" for the namespace "Order.Items[3].Price". Names come
+// from the function registered with RegisterTagNameFunc, if any.
+func Field(fe validator.FieldError) string {
+    ns := fe.Namespace()
+    if i := strings.IndexAny(ns, ".["); i >= 0 {
+        if ns[i] == '.' {
+            return ns[i+1:]
+        }
+
+        return ns[i:]
+    }
+
+    return ns
+}
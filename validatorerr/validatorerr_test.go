@@ -0,0 +1,46 @@
+package validatorerr
+
+import (
+    "testing"
+
+    "github.com/go-playground/validator/v10"
+    "github.com/onedaycat/errors"
+    "github.com/stretchr/testify/require"
+)
+
+type item struct {
+    Price int `validate:"min=1"`
+}
+
+type order struct {
+    Name  string `validate:"required"`
+    Items []item `validate:"dive"`
+}
+
+func TestTranslate(t *testing.T) {
+    require.Nil(t, Translate(nil))
+
+    verr := validator.New().Struct(&order{Items: []item{{Price: 10}, {Price: 0}}})
+    err := Translate(verr)
+    require.EqualError(t, err, "ValidationFailed: validation failed")
+    require.Equal(t, errors.BadRequestType, err.GetType())
+    require.Equal(t, []*errors.Violation{
+        {Field: "Name", Rule: "required", Message: "failed on the 'required' rule", Value: ""},
+        {Field: "Items[1].Price", Rule: "min", Message: "failed on the 'min=1' rule", Value: 0},
+    }, err.GetViolations())
+    require.Contains(t, err.Unwrap().Error(), "'order.Name'")
+
+    tr := &Translator{
+        Code:     "InvalidOrder",
+        Message:  "invalid order",
+        Rule:     func(fe validator.FieldError) string { return "order." + fe.Tag() },
+        Describe: func(fe validator.FieldError) string { return fe.Field() + " is invalid" },
+    }
+    err = tr.Translate(verr)
+    require.EqualError(t, err, "InvalidOrder: invalid order")
+    require.Equal(t, "order.required", err.GetViolations()[0].Rule)
+    require.Equal(t, "Price is invalid", err.GetViolations()[1].Message)
+
+    err = Translate(validator.New().Struct(nil))
+    require.Equal(t, errors.InternalErrorType, err.GetType())
+}
@@ -0,0 +1,152 @@
+package errors
+
+import (
+    "bytes"
+    "encoding/json"
+    "reflect"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// WireVersion is the version of the JSONError format written by this package.
+// Documents with a higher version are decoded on a best effort basis.
+const WireVersion = 1
+
+var (
+    inputTypesMu sync.RWMutex
+    inputTypes   = make(map[string]reflect.Type)
+    inputNames   = make(map[reflect.Type]string)
+)
+
+// RegisterInputType registers the type of v under name. Inputs of that type
+// are encoded with their name and decoded back into the same type, instead of
+// the generic values encoding/json produces.
+func RegisterInputType(name string, v interface{}) {
+    t := reflect.TypeOf(v)
+
+    inputTypesMu.Lock()
+    inputTypes[name] = t
+    inputNames[t] = name
+    inputTypesMu.Unlock()
+}
+
+// resetInputTypes forgets all registered input types.
+func resetInputTypes() {
+    inputTypesMu.Lock()
+    inputTypes = make(map[string]reflect.Type)
+    inputNames = make(map[reflect.Type]string)
+    inputTypesMu.Unlock()
+}
+
+func inputTypeName(input interface{}) string {
+    inputTypesMu.RLock()
+    defer inputTypesMu.RUnlock()
+
+    return inputNames[reflect.TypeOf(input)]
+}
+
+func decodeInput(name string, raw json.RawMessage) (interface{}, bool, error) {
+    inputTypesMu.RLock()
+    t, ok := inputTypes[name]
+    inputTypesMu.RUnlock()
+
+    if !ok {
+        return nil, false, nil
+    }
+
+    if t.Kind() == reflect.Ptr {
+        v := reflect.New(t.Elem())
+        if err := json.Unmarshal(raw, v.Interface()); err != nil {
+            return nil, false, err
+        }
+
+        return v.Interface(), true, nil
+    }
+
+    v := reflect.New(t)
+    if err := json.Unmarshal(raw, v.Interface()); err != nil {
+        return nil, false, err
+    }
+
+    return v.Elem().Interface(), true, nil
+}
+
+// jsonErrorMembers is JSONError without its methods, so that encoding/json
+// handles the known members.
+type jsonErrorMembers JSONError
+
+var jsonErrorKeys = func() map[string]bool {
+    keys := make(map[string]bool)
+    t := reflect.TypeOf(JSONError{})
+    for i := 0; i < t.NumField(); i++ {
+        name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+        if name != "" && name != "-" {
+            keys[name] = true
+        }
+    }
+
+    return keys
+}()
+
+func (e *JSONError) MarshalJSON() ([]byte, error) {
+    b, err := json.Marshal((*jsonErrorMembers)(e))
+    if err != nil || len(e.Extra) == 0 {
+        return b, err
+    }
+
+    keys := make([]string, 0, len(e.Extra))
+    for key := range e.Extra {
+        if !jsonErrorKeys[key] {
+            keys = append(keys, key)
+        }
+    }
+    sort.Strings(keys)
+
+    buf := bytes.NewBuffer(b[:len(b)-1])
+    for _, key := range keys {
+        if buf.Len() > 1 {
+            buf.WriteByte(',')
+        }
+        name, _ := json.Marshal(key)
+        buf.Write(name)
+        buf.WriteByte(':')
+        buf.Write(e.Extra[key])
+    }
+    buf.WriteByte('}')
+
+    return buf.Bytes(), nil
+}
+
+func (e *JSONError) UnmarshalJSON(b []byte) error {
+    var members map[string]json.RawMessage
+    if err := json.Unmarshal(b, &members); err != nil {
+        return err
+    }
+
+    if err := json.Unmarshal(b, (*jsonErrorMembers)(e)); err != nil {
+        return err
+    }
+
+    if raw, ok := members["input"]; ok && e.InputType != "" {
+        input, ok, err := decodeInput(e.InputType, raw)
+        if err != nil {
+            return err
+        }
+        if ok {
+            e.Input = input
+        }
+    }
+
+    for key, raw := range members {
+        if jsonErrorKeys[key] {
+            continue
+        }
+        if e.Extra == nil {
+            e.Extra = make(map[string]json.RawMessage)
+        }
+        e.Extra[key] = raw
+    }
+
+    return nil
+}
@@ -0,0 +1,62 @@
+package errors
+
+import (
+    "encoding/json"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+type wireInput struct {
+    ID    string `json:"id"`
+    Count int    `json:"count"`
+}
+
+func TestWireVersion(t *testing.T) {
+    b, err := json.Marshal(x().(Error).JSON())
+    require.NoError(t, err)
+
+    jsonErr := &JSONError{}
+    require.NoError(t, json.Unmarshal(b, jsonErr))
+    require.Equal(t, WireVersion, jsonErr.V)
+    require.Equal(t, 0, jsonErr.Cause.V)
+}
+
+func TestWireUnknownMembers(t *testing.T) {
+    in := `{"v":2,"code":"code1","message":"err1","retryAfter":30,` +
+        `"cause":{"code":"code0","message":"err0","region":{"name":"eu"}}}`
+
+    jsonErr := &JSONError{}
+    require.NoError(t, json.Unmarshal([]byte(in), jsonErr))
+    require.Equal(t, 2, jsonErr.V)
+    require.Equal(t, json.RawMessage(`30`), jsonErr.Extra["retryAfter"])
+
+    b, err := json.Marshal(ParseJSONError(jsonErr).JSON())
+    require.NoError(t, err)
+    require.JSONEq(t, `{"v":1,"code":"code1","message":"err1","retryAfter":30,`+
+        `"cause":{"code":"code0","message":"err0","region":{"name":"eu"}}}`, string(b))
+}
+
+func TestWireInputType(t *testing.T) {
+    defer resetInputTypes()
+    RegisterInputType("wireInput", wireInput{})
+    RegisterInputType("*wireInput", &wireInput{})
+
+    err := BadRequest("code2", "err2").
+        WithInput(wireInput{ID: "a", Count: 1}).
+        WithCause(BadRequest("code1", "err1").WithInput(&wireInput{ID: "b", Count: 2}))
+
+    b, jerr := json.Marshal(err)
+    require.NoError(t, jerr)
+
+    newErr := &GenericError{}
+    require.NoError(t, json.Unmarshal(b, newErr))
+    require.Equal(t, []interface{}{
+        wireInput{ID: "a", Count: 1},
+        &wireInput{ID: "b", Count: 2},
+    }, newErr.GetAllInputs())
+
+    newErr = &GenericError{}
+    require.NoError(t, json.Unmarshal([]byte(`{"code":"code1","input":{"id":"a"},"inputType":"unknown"}`), newErr))
+    require.Equal(t, map[string]interface{}{"id": "a"}, newErr.GetInput())
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+    "context"
+    stderrors "errors"
+    "fmt"
+    "os"
+    "testing"
+
+    "github.com/stretchr/testify/require"
+)
+
+func loadConfig() error {
+    _, err := os.Open("/does/not/exist")
+    return Wrap(err, "loading config")
+}
+
+func TestWrap(t *testing.T) {
+    require.Nil(t, Wrap(nil, "msg"))
+    require.Nil(t, Wrapf(nil, "msg %d", 1))
+    require.Nil(t, WrapWithDef(DefNotFound("wrap1", "err1"), nil))
+    require.Nil(t, WrapType(nil, TimeoutType, "code1"))
+
+    err := loadConfig().(Error)
+    require.EqualError(t, err, "loading config: open /does/not/exist: no such file or directory")
+    require.Equal(t, err.Error(), fmt.Sprint(err))
+    require.True(t, stderrors.Is(err, os.ErrNotExist))
+    var pathErr *os.PathError
+    require.True(t, stderrors.As(err, &pathErr))
+    require.Equal(t, "/does/not/exist", pathErr.Path)
+    require.Equal(t, "loadConfig", err.GetStacktrace()[len(err.GetStacktrace())-1].Function)
+    require.Equal(t, "loadConfig", err.RootError().GetStacktrace()[len(err.RootError().GetStacktrace())-1].Function)
+
+    cause := NotFound("code1", "err1")
+    err = Wrapf(cause, "loading user %d", 42)
+    require.EqualError(t, err, "loading user 42: code1: err1")
+    require.Equal(t, "loading user 42: code1: err1", fmt.Sprint(err))
+    require.EqualError(t, ParseJSONError(err.JSON()), err.Error())
+    require.EqualError(t, ParseJSONError(err.JSON().Compact()), err.Error())
+    b, _ := err.(*GenericError).MarshalBinary()
+    decoded := &GenericError{}
+    require.NoError(t, decoded.UnmarshalBinary(b))
+    require.EqualError(t, decoded, err.Error())
+    require.EqualError(t, InternalError("code2", "err2").WithCause(err), "code2: err2")
+    require.Equal(t, NotFoundType, err.GetType())
+    require.True(t, err.Is(cause))
+    require.True(t, stderrors.Is(err, cause))
+    require.Same(t, cause, err.Unwrap())
+    require.Equal(t, "TestWrap", cause.GetStacktrace()[len(cause.GetStacktrace())-1].Function)
+
+    require.Same(t, cause, Wrap(cause))
+    require.False(t, stderrors.Is(Wrap(cause, "loading"), New("unrelated")))
+    require.False(t, Is(Wrapf(cause, "loading"), Wrap(stderrors.New("unrelated"), "loading")))
+    wrapped := Wrap(cause, "loading")
+    require.True(t, Is(Wrap(wrapped, "handling"), wrapped))
+    require.EqualError(t, Wrap(context.Canceled), "context canceled")
+    require.Equal(t, context.Canceled, Wrap(context.Canceled).Unwrap())
+}
+
+func TestWrapWithDef(t *testing.T) {
+    def := DefNotFound("wrap2", "user not found")
+    err := WrapWithDef(def, os.ErrNotExist)
+    require.EqualError(t, err, "wrap2: user not found")
+    require.Equal(t, NotFoundType, err.GetType())
+    require.True(t, def.Is(err))
+    require.True(t, def.Is(Wrap(err, "loading user")))
+    require.True(t, stderrors.Is(err, os.ErrNotExist))
+    require.True(t, Is(err, os.ErrNotExist))
+    require.Equal(t, "TestWrapWithDef", err.GetStacktrace()[len(err.GetStacktrace())-1].Function)
+
+    err = WrapWithDef(DefTimeout("wrap3"), context.DeadlineExceeded)
+    require.EqualError(t, err, "wrap3: context deadline exceeded")
+}
+
+func TestWrapType(t *testing.T) {
+    err := WrapType(context.DeadlineExceeded, TimeoutType, "QueryTimeout")
+    require.EqualError(t, err, "QueryTimeout: context deadline exceeded")
+    require.Equal(t, TimeoutType, err.GetType())
+    require.True(t, err.IsType(TimeoutType))
+    require.False(t, err.IsType(NotFoundType))
+    require.True(t, stderrors.Is(err, context.DeadlineExceeded))
+    require.False(t, stderrors.Is(err, context.Canceled))
+}